@@ -0,0 +1,147 @@
+package authorize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+// flags shared by the commands logging in a user
+var (
+	issuer    string
+	clientID  string
+	scopes    []string
+	projects  []string
+	orgID     string
+	orgDomain string
+	roles     []string
+	prompt    string
+	loginHint string
+	timeout   time.Duration
+)
+
+func registerFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&issuer, "issuer", "", "issuer of your ZITADEL instance, used to discover the endpoints (e.g. https://<your domain>)")
+	flags.StringVar(&clientID, "id", "", "Client ID as string")
+	flags.StringSliceVar(&scopes, "scope", []string{"openid", "profile", "email"}, "scopes to request; can be repeated")
+	flags.StringSliceVar(&projects, "project", nil, "ID of a project to add to the audience of the token (urn:zitadel:iam:org:project:id:{id}:aud); can be repeated")
+	flags.StringVar(&orgID, "org-id", "", "ID of the organization the user must log in to (urn:zitadel:iam:org:id:{id})")
+	flags.StringVar(&orgDomain, "org-domain", "", "primary domain of the organization the user must log in to (urn:zitadel:iam:org:domain:primary:{domain})")
+	flags.StringSliceVar(&roles, "role", nil, "key of a role to request (urn:zitadel:iam:org:project:role:{key}); can be repeated")
+	flags.DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+}
+
+// registerPromptFlags registers the flags of the authorization request which only apply to the authorization code flow.
+func registerPromptFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&prompt, "prompt", "", "prompt parameter, e.g. login, consent, select_account or create")
+	flags.StringVar(&loginHint, "login-hint", "", "login name to prefill on the login page")
+}
+
+// requestedScopes returns the scopes extended by the reserved scopes of the project, organization and role flags
+// and checks the syntax of all reserved scopes.
+func requestedScopes() ([]string, error) {
+	requested := oauth.WithProjectAudiences(scopes, projects)
+	if orgID != "" {
+		requested = append(requested, oauth.OrgIDScope(orgID))
+	}
+	if orgDomain != "" {
+		requested = append(requested, oauth.OrgDomainScope(orgDomain))
+	}
+	for _, role := range roles {
+		requested = append(requested, oauth.RoleScope(role))
+	}
+	if err := oauth.ValidateScopes(requested); err != nil {
+		return nil, err
+	}
+	return requested, nil
+}
+
+// authorization is a prepared authorization code request with the values needed to exchange the code.
+type authorization struct {
+	URL           string `json:"url"`
+	State         string `json:"state"`
+	Nonce         string `json:"nonce"`
+	CodeVerifier  string `json:"code_verifier"`
+	RedirectURI   string `json:"redirect_uri"`
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+// prepare discovers the endpoints of the issuer and builds the authorization request for redirectURI
+// with a new PKCE pair, state and nonce. Problems which don't prevent the request are written to log.
+func prepare(ctx context.Context, client *http.Client, redirectURI string, log io.Writer) (*authorization, *oidc.DiscoveryConfiguration, error) {
+	if issuer == "" || clientID == "" {
+		return nil, nil, errors.New("please provide an issuer and client ID")
+	}
+	requested, err := requestedScopes()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(requested, oidc.ScopeOpenID) {
+		fmt.Fprintln(log, "WARN: the openid scope is missing, so no ID token is issued and the nonce is not checked")
+	}
+	config, err := oauth.Discover(ctx, client, issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkConfiguration(config); err != nil {
+		return nil, nil, err
+	}
+
+	pkce, err := oauth.NewPKCE()
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := oauth.RandomValue()
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := oauth.RandomValue()
+	if err != nil {
+		return nil, nil, err
+	}
+	authorizeURL, err := oauth.AuthorizeURL(config.AuthorizationEndpoint, &oauth.AuthorizeRequest{
+		ClientID:    clientID,
+		RedirectURI: redirectURI,
+		Scopes:      requested,
+		State:       state,
+		Nonce:       nonce,
+		PKCE:        pkce,
+		Prompt:      prompt,
+		LoginHint:   loginHint,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &authorization{
+		URL:           authorizeURL,
+		State:         state,
+		Nonce:         nonce,
+		CodeVerifier:  pkce.Verifier,
+		RedirectURI:   redirectURI,
+		TokenEndpoint: config.TokenEndpoint,
+	}, config, nil
+}
+
+// checkConfiguration ensures the issuer supports the authorization code flow with PKCE.
+func checkConfiguration(config *oidc.DiscoveryConfiguration) error {
+	if config.AuthorizationEndpoint == "" {
+		return errors.New("discovery: authorization_endpoint is missing")
+	}
+	if len(config.ResponseTypesSupported) > 0 && !slices.Contains(config.ResponseTypesSupported, string(oidc.ResponseTypeCode)) {
+		return fmt.Errorf("discovery: response type code is not supported, only %s", strings.Join(config.ResponseTypesSupported, ", "))
+	}
+	if len(config.CodeChallengeMethodsSupported) > 0 && !slices.Contains(config.CodeChallengeMethodsSupported, oidc.CodeChallengeMethodS256) {
+		return errors.New("discovery: PKCE with S256 is not supported")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package authorize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+// DeviceCmd represents the device-login command
+var DeviceCmd = &cobra.Command{
+	Use:   "device-login",
+	Short: "Log in a user with the device authorization grant, e.g. on machines without a browser",
+	Long: `Request a device and user code and show the verification URI, where the user logs in on another device.
+The token endpoint is polled until the user approved the request, honouring the interval and slow_down responses of the server.
+The device code grant type must be enabled for the application.
+The ID token is verified with the keys of the issuer.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return deviceLogin(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+var showQRCode bool
+
+func init() {
+	registerFlags(DeviceCmd.Flags())
+	registerTokenFlags(DeviceCmd.Flags())
+	DeviceCmd.Flags().BoolVar(&showQRCode, "qr", false, "show the verification URI as QR code in the terminal")
+}
+
+func deviceLogin(ctx context.Context, out, log io.Writer) error {
+	if issuer == "" || clientID == "" {
+		return errors.New("please provide an issuer and client ID")
+	}
+	clientAuth, err := checkTokenFlags(log)
+	if err != nil {
+		return err
+	}
+	requested, err := requestedScopes()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := &http.Client{Timeout: timeout}
+	config, err := oauth.Discover(ctx, client, issuer)
+	if err != nil {
+		return err
+	}
+	if config.DeviceAuthorizationEndpoint == "" {
+		return errors.New("discovery: device_authorization_endpoint is missing, the issuer does not support the device authorization grant")
+	}
+	device, err := oauth.DeviceAuthorization(ctx, client, config.DeviceAuthorizationEndpoint, clientID, requested, clientAuth)
+	if err != nil {
+		return fmt.Errorf("device authorization request: %w", err)
+	}
+
+	fmt.Fprintf(log, "To log in, open %s and enter the code\n\n  %s\n\n", device.VerificationURI, device.UserCode)
+	completeURI := device.VerificationURI
+	if device.VerificationURIComplete != "" {
+		completeURI = device.VerificationURIComplete
+		fmt.Fprintf(log, "or open %s\n\n", completeURI)
+	}
+	if showQRCode {
+		if err = output.WriteQRCode(log, completeURI); err != nil {
+			return err
+		}
+		fmt.Fprintln(log)
+	}
+	fmt.Fprintln(log, "Waiting for the login to be approved...")
+
+	resp, err := oauth.PollDeviceToken(ctx, client, config.TokenEndpoint, clientID, device, clientAuth)
+	if err != nil {
+		return fmt.Errorf("token request: %w", err)
+	}
+	if err = verifyIDToken(ctx, client, config, resp, "", log); err != nil {
+		return err
+	}
+	return printTokens(out, log, resp)
+}
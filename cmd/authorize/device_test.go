@@ -0,0 +1,53 @@
+package authorize
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+func Test_deviceLogin(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		qrCode  bool
+		wantErr bool
+	}{
+		{
+			name:    "denied",
+			wantErr: true,
+		},
+		{
+			name:   "approved",
+			user:   "user",
+			qrCode: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := mockoptest.Start(t, mockop.Config{User: tt.user})
+			issuer, clientID, scopes, projects, orgID, orgDomain, roles = op.Issuer(), "client", []string{"openid"}, nil, "", "", nil
+			timeout, showQRCode = 10*time.Second, tt.qrCode
+			outputFlags.Format, full, outputPath = "raw", false, ""
+
+			var out, log bytes.Buffer
+			err := deviceLogin(context.Background(), &out, &log)
+			assert.Regexp(t, `\n  [A-Z]{4}-[A-Z]{4}\n`, log.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Regexp(t, `^[\w-]+\.[\w-]+\.[\w-]+\n$`, out.String())
+			assert.Contains(t, log.String(), "▀")
+			assert.Contains(t, log.String(), "Logged in as user")
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package authorize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/loopback"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+// LoginCmd represents the login command
+var LoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in a user with the authorization code flow and PKCE and print the tokens",
+	Long: `Start a redirect listener on a loopback address, print or open the authorization URL
+and exchange the received code for tokens, e.g. to test APIs with the access token of a user.
+The redirect URI http://127.0.0.1:<port>/callback (or the host of --listen) must be allowed for the application;
+ZITADEL allows any port for loopback redirect URIs of native applications.
+The ID token is verified with the keys of the issuer.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return login(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+const callbackPath = "/callback"
+
+var (
+	listen  string
+	openURL bool
+	wait    time.Duration
+)
+
+// openBrowser opens the URL in the default browser of the user.
+var openBrowser = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+func init() {
+	registerFlags(LoginCmd.Flags())
+	registerPromptFlags(LoginCmd.Flags())
+	registerTokenFlags(LoginCmd.Flags())
+	LoginCmd.Flags().StringVar(&listen, "listen", "127.0.0.1:0", "loopback address of the redirect listener; port 0 picks a random port")
+	LoginCmd.Flags().BoolVar(&openURL, "open", false, "open the authorization URL in the default browser")
+	LoginCmd.Flags().DurationVar(&wait, "wait", 5*time.Minute, "how long to wait for the login to finish")
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+func login(ctx context.Context, out, log io.Writer) error {
+	if err := loopback.CheckAddress(listen); err != nil {
+		return err
+	}
+	clientAuth, err := checkTokenFlags(log)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	host, _, _ := net.SplitHostPort(listen)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	redirectURI := "http://" + net.JoinHostPort(host, port) + callbackPath
+
+	client := &http.Client{Timeout: timeout}
+	auth, config, err := prepare(ctx, client, redirectURI, log)
+	if err != nil {
+		return err
+	}
+
+	results := make(chan callbackResult, 1)
+	srv := &http.Server{
+		Handler:           callbackHandler(auth.State, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	fmt.Fprintf(log, "Open the following URL to log in:\n\n  %s\n\n", auth.URL)
+	if openURL {
+		if err = openBrowser(auth.URL); err != nil {
+			fmt.Fprintf(log, "WARN: could not open the browser: %v\n", err)
+		}
+	}
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return fmt.Errorf("login did not finish within %s", wait)
+	}
+	if result.err != nil {
+		return result.err
+	}
+
+	resp, err := oauth.AuthorizationCode(ctx, client, auth.TokenEndpoint, clientID, result.code, redirectURI, auth.CodeVerifier, clientAuth)
+	if err != nil {
+		return fmt.Errorf("token request: %w", err)
+	}
+	if err = verifyIDToken(ctx, client, config, resp, auth.Nonce, log); err != nil {
+		return err
+	}
+	return printTokens(out, log, resp)
+}
+
+// callbackHandler sends the code or error of the first redirect with the expected state to results.
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	var once sync.Once
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "The state does not match the login request, please start the login again.", http.StatusBadRequest)
+			return
+		}
+		result := callbackResult{code: query.Get("code")}
+		message := "Login finished, you can close this window."
+		switch {
+		case query.Get("error") != "":
+			result.err = &oauth.Error{Code: query.Get("error"), Description: query.Get("error_description")}
+			message = "Login failed: " + result.err.Error()
+		case result.code == "":
+			result.err = errors.New("redirect contains no code")
+			message = "Login failed: no code received."
+		}
+		once.Do(func() { results <- result })
+		fmt.Fprintln(w, message)
+	})
+	return mux
+}
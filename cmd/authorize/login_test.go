@@ -0,0 +1,126 @@
+package authorize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+// browser returns a replacement of openBrowser following the redirects of the authorize URL,
+// which sends the nonce instead of the one of the request, if not empty.
+func browser(nonce string) func(string) error {
+	return func(u string) error {
+		if nonce != "" {
+			parsed, err := url.Parse(u)
+			if err != nil {
+				return err
+			}
+			query := parsed.Query()
+			query.Set("nonce", nonce)
+			parsed.RawQuery = query.Encode()
+			u = parsed.String()
+		}
+		resp, err := http.Get(u)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+}
+
+func Test_login(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        string
+		nonce       string
+		full        bool
+		outputPath  string
+		accessToken bool
+		wantErr     bool
+	}{
+		{
+			name:    "access denied",
+			wantErr: true,
+		},
+		{
+			name:    "nonce mismatch",
+			user:    "user",
+			nonce:   "other",
+			wantErr: true,
+		},
+		{
+			name:        "access token",
+			user:        "user",
+			accessToken: true,
+		},
+		{
+			name: "full",
+			user: "user",
+			full: true,
+		},
+		{
+			name:        "output file",
+			user:        "user",
+			outputPath:  filepath.Join(t.TempDir(), "token"),
+			accessToken: true,
+		},
+		{
+			name:       "full output file",
+			user:       "user",
+			full:       true,
+			outputPath: filepath.Join(t.TempDir(), "tokens.json"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := mockoptest.Start(t, mockop.Config{User: tt.user})
+			openBrowser = browser(tt.nonce)
+			issuer, clientID, scopes, projects, orgID, orgDomain, roles = op.Issuer(), "client", []string{"openid"}, nil, "", "", nil
+			listen, openURL, wait, timeout = "127.0.0.1:0", true, 10*time.Second, 10*time.Second
+			outputFlags.Format, full, outputPath = "raw", tt.full, tt.outputPath
+
+			var out bytes.Buffer
+			err := login(context.Background(), &out, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			data := out.Bytes()
+			if tt.outputPath != "" {
+				assert.Empty(t, data)
+				info, err := os.Stat(tt.outputPath)
+				require.NoError(t, err)
+				assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+				data, err = os.ReadFile(tt.outputPath)
+				require.NoError(t, err)
+			}
+			if tt.accessToken {
+				assert.Regexp(t, `^[\w-]+\.[\w-]+\.[\w-]+\n$`, string(data))
+				return
+			}
+			var tokens map[string]any
+			require.NoError(t, json.Unmarshal(data, &tokens))
+			assert.NotEmpty(t, tokens["access_token"])
+			assert.NotEmpty(t, tokens["id_token"])
+		})
+	}
+}
+
+func Test_login_foreignListener(t *testing.T) {
+	listen = "0.0.0.0:0"
+	assert.Error(t, login(context.Background(), io.Discard, io.Discard))
+}
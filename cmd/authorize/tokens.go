@@ -0,0 +1,98 @@
+package authorize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/internal/secret"
+)
+
+// flags shared by the commands receiving the tokens of a user
+var (
+	clientSecret secret.Flags
+	outputFlags  output.Flags
+	full         bool
+	outputPath   string
+)
+
+func registerTokenFlags(flags *pflag.FlagSet) {
+	clientSecret.Register(flags, "secret", "client secret of a confidential application")
+	outputFlags.Register(flags)
+	flags.BoolVar(&full, "full", false, "print the full token response with ID, access and refresh token as JSON instead of the access token only")
+	flags.StringVar(&outputPath, "output", "", "path to a file to write the tokens to instead of printing them, in the format of --format or --full (written with mode 0600)")
+}
+
+// checkTokenFlags validates the output flags and returns the client authentication,
+// which is nil for public clients without secret.
+func checkTokenFlags(log io.Writer) (oauth.ClientAuth, error) {
+	if err := outputFlags.Validate(); err != nil {
+		return nil, err
+	}
+	if full && outputFlags.Format != output.FormatRaw {
+		return nil, errors.New("--full can't be combined with --format")
+	}
+	if !clientSecret.Provided() {
+		return nil, nil
+	}
+	value, err := clientSecret.Read(os.Stdin, log)
+	if err != nil {
+		return nil, err
+	}
+	return oauth.ClientSecretBasic(clientID, value), nil
+}
+
+// verifyIDToken verifies the ID token of the response, if any, with the keys of the issuer.
+func verifyIDToken(ctx context.Context, client *http.Client, config *oidc.DiscoveryConfiguration, resp *oauth.TokenResponse, nonce string, log io.Writer) error {
+	if resp.IDToken == "" {
+		return nil
+	}
+	keys, err := oauth.FetchKeys(ctx, client, config.JwksURI)
+	if err != nil {
+		return err
+	}
+	claims, err := oauth.VerifyIDToken(resp.IDToken, keys, issuer, clientID, nonce, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(log, "Logged in as %s\n", claims.Subject)
+	return nil
+}
+
+// printTokens prints the access token in the requested format or the full token response as JSON,
+// to the output file if one is set.
+func printTokens(out, log io.Writer, resp *oauth.TokenResponse) error {
+	data, err := renderTokens(resp)
+	if err != nil {
+		return err
+	}
+	if outputPath == "" {
+		_, err = out.Write(data)
+		return err
+	}
+	if err = output.WriteFile(outputPath, data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(log, "Stored the tokens in %s\n", outputPath)
+	return nil
+}
+
+func renderTokens(resp *oauth.TokenResponse) ([]byte, error) {
+	if !full {
+		return outputFlags.Render(output.FromResponse(resp))
+	}
+	data, err := resp.IndentedJSON()
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,68 @@
+package authorize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+// URLCmd represents the authorize-url command
+var URLCmd = &cobra.Command{
+	Use:   "authorize-url",
+	Short: "Build an authorization URL with PKCE, state and nonce",
+	Long: `Discover the authorization endpoint of the issuer and build the URL of an authorization code request
+with a new PKCE code verifier and challenge, state and nonce.
+The reserved ZITADEL scopes are checked for syntax errors.
+Besides the URL, the values needed for the later code exchange are printed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return authorizeURL(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+var (
+	redirectURI string
+	urlFormat   string
+)
+
+func init() {
+	registerFlags(URLCmd.Flags())
+	registerPromptFlags(URLCmd.Flags())
+	URLCmd.Flags().StringVar(&redirectURI, "redirect-uri", "", "redirect URI registered for the application")
+	URLCmd.Flags().StringVar(&urlFormat, "format", output.FormatText, "output format: text or json")
+}
+
+func authorizeURL(ctx context.Context, out, log io.Writer) error {
+	if redirectURI == "" {
+		return errors.New("please provide a redirect URI")
+	}
+	if err := output.CheckFormat(urlFormat, output.FormatText, output.FormatJSON); err != nil {
+		return err
+	}
+	auth, _, err := prepare(ctx, &http.Client{Timeout: timeout}, redirectURI, log)
+	if err != nil {
+		return err
+	}
+	if urlFormat == output.FormatJSON {
+		data, err := json.MarshalIndent(auth, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	fmt.Fprintln(out, auth.URL)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "state:          %s\n", auth.State)
+	fmt.Fprintf(out, "nonce:          %s\n", auth.Nonce)
+	fmt.Fprintf(out, "code_verifier:  %s\n", auth.CodeVerifier)
+	fmt.Fprintf(out, "redirect_uri:   %s\n", auth.RedirectURI)
+	fmt.Fprintf(out, "token_endpoint: %s\n", auth.TokenEndpoint)
+	return nil
+}
@@ -0,0 +1,96 @@
+package authorize
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+func Test_authorizeURL(t *testing.T) {
+	discovery := map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(discovery)
+	}))
+	defer server.Close()
+	valid := map[string]any{
+		"issuer":                           server.URL,
+		"authorization_endpoint":           server.URL + "/oauth/v2/authorize",
+		"token_endpoint":                   server.URL + "/oauth/v2/token",
+		"response_types_supported":         []string{"code", "id_token"},
+		"code_challenge_methods_supported": []string{"S256"},
+	}
+
+	tests := []struct {
+		name      string
+		discovery map[string]any
+		orgID     string
+		roles     []string
+		wantScope string
+		wantErr   bool
+	}{
+		{
+			name:      "invalid role scope",
+			discovery: valid,
+			roles:     []string{""},
+			wantErr:   true,
+		},
+		{
+			name: "no PKCE",
+			discovery: map[string]any{
+				"issuer":                           server.URL,
+				"authorization_endpoint":           server.URL + "/oauth/v2/authorize",
+				"code_challenge_methods_supported": []string{"plain"},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "valid",
+			discovery: valid,
+			orgID:     "123",
+			roles:     []string{"admin"},
+			wantScope: "openid profile urn:zitadel:iam:org:project:id:456:aud urn:zitadel:iam:org:id:123 urn:zitadel:iam:org:project:role:admin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discovery = tt.discovery
+			issuer, clientID, redirectURI, urlFormat = server.URL, "client", "http://localhost:8080/callback", output.FormatJSON
+			scopes, projects, orgID, orgDomain, roles = []string{"openid", "profile"}, []string{"456"}, tt.orgID, "", tt.roles
+
+			var out bytes.Buffer
+			err := authorizeURL(context.Background(), &out, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			got := new(authorization)
+			require.NoError(t, json.Unmarshal(out.Bytes(), got))
+			assert.Equal(t, server.URL+"/oauth/v2/token", got.TokenEndpoint)
+
+			u, err := url.Parse(got.URL)
+			require.NoError(t, err)
+			query := u.Query()
+			assert.Equal(t, "/oauth/v2/authorize", u.Path)
+			assert.Equal(t, "client", query.Get("client_id"))
+			assert.Equal(t, tt.wantScope, query.Get("scope"))
+			assert.Equal(t, got.State, query.Get("state"))
+			assert.Equal(t, got.Nonce, query.Get("nonce"))
+			hash := sha256.Sum256([]byte(got.CodeVerifier))
+			assert.Equal(t, base64.RawURLEncoding.EncodeToString(hash[:]), query.Get("code_challenge"))
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package basicauth
 
 import (
-	b64 "encoding/base64"
+	"errors"
 	"fmt"
 	"log"
-	"net/url"
+	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/secret"
 )
 
 // Cmd represents the basicauth command
@@ -19,25 +22,32 @@ var Cmd = &cobra.Command{
 }
 
 var (
-	clientId     string
-	clientSecret string
+	clientId    string
+	secretFlags secret.Flags
 )
 
 func init() {
 	Cmd.Flags().StringVar(&clientId, "id", "", "Client ID as string")
-	Cmd.Flags().StringVar(&clientSecret, "secret", "", "Client secret as string")
+	secretFlags.Register(Cmd.Flags(), "secret", "client secret")
+	Cmd.AddCommand(decodeCmd)
 }
 
 func basicAuth(cmd *cobra.Command) {
-	if clientId == "" || clientSecret == "" {
+	if clientId == "" {
 		log.Println("please provide a client ID and secret")
 		fmt.Println(cmd.Flags().FlagUsages())
 		return
 	}
+	clientSecret, err := secretFlags.Read(os.Stdin, os.Stderr)
+	if errors.Is(err, secret.ErrMissing) {
+		log.Println(err)
+		fmt.Println(cmd.Flags().FlagUsages())
+		return
+	}
+	if err != nil {
+		log.Fatalf("error reading client secret: %v", err.Error())
+		return
+	}
 
-	sEscaped := url.QueryEscape(clientId) + ":" + url.QueryEscape(clientSecret)
-
-	sEnc := b64.StdEncoding.EncodeToString([]byte(sEscaped))
-
-	fmt.Println(sEnc)
+	fmt.Println(oauth.BasicAuth(clientId, clientSecret))
 }
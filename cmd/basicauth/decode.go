@@ -0,0 +1,58 @@
+package basicauth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+var decodeCmd = &cobra.Command{
+	Use:   "decode [<header> | -]",
+	Short: "Decode an Authorization header for Client Secret Basic into <client ID> and <client secret>",
+	Long: `Decode an Authorization header ("Authorization: Basic ..."), its value or the base64 encoded credentials
+into the client ID and secret, reversing the form-encoding of both.
+The header is read from the first argument or from standard input if it is omitted or "-".
+A warning is printed if the credentials were not form-encoded, which ZITADEL requires.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return decode(cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr(), args)
+	},
+}
+
+func decode(in io.Reader, out, log io.Writer, args []string) error {
+	value, err := readHeader(in, args)
+	if err != nil {
+		return err
+	}
+	credentials, err := oauth.ParseBasicAuth(value)
+	if err != nil {
+		return err
+	}
+	if credentials.NotFormEncoded {
+		fmt.Fprintln(log, "WARN: the client ID or secret is not form-encoded (application/x-www-form-urlencoded) as required by RFC 6749 and ZITADEL,")
+		fmt.Fprintln(log, "      which causes invalid_client errors; the values below are shown as sent, use basicauth to encode them correctly")
+	}
+	fmt.Fprintf(out, "client ID:     %s\n", credentials.ClientID)
+	fmt.Fprintf(out, "client secret: %s\n", credentials.ClientSecret)
+	return nil
+}
+
+func readHeader(in io.Reader, args []string) (string, error) {
+	if len(args) == 1 && args[0] != "-" {
+		return args[0], nil
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", errors.New("please provide an Authorization header as argument or on stdin")
+	}
+	return value, nil
+}
@@ -0,0 +1,107 @@
+package clientcredentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/internal/secret"
+)
+
+// Cmd represents the client-credentials command
+var Cmd = &cobra.Command{
+	Use:   "client-credentials",
+	Short: "Request an <access token> for <client ID> and <client secret> using the client credentials grant",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return clientCredentials(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+var (
+	issuer      string
+	clientId    string
+	secretFlags secret.Flags
+	authMethod  string
+	scopes      []string
+	projects    []string
+	full        bool
+	outputFlags output.Flags
+	timeout     time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&issuer, "issuer", "", "issuer of your ZITADEL instance, used to discover the token endpoint (e.g. https://<your domain>)")
+	Cmd.Flags().StringVar(&clientId, "id", "", "Client ID as string")
+	secretFlags.Register(Cmd.Flags(), "secret", "client secret")
+	Cmd.Flags().StringVar(&authMethod, "auth-method", oauth.AuthMethodBasic, "client authentication method: client_secret_basic or client_secret_post")
+	Cmd.Flags().StringSliceVar(&scopes, "scope", []string{"openid", oauth.ScopeZITADELAudience}, "scopes to request; can be repeated")
+	Cmd.Flags().StringSliceVar(&projects, "project", nil, "ID of a project to add to the audience of the token (urn:zitadel:iam:org:project:id:{id}:aud); can be repeated")
+	Cmd.Flags().BoolVar(&full, "full", false, "print the full token response as JSON instead of the access token only")
+	outputFlags.Register(Cmd.Flags())
+	Cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+}
+
+func clientCredentials(ctx context.Context, out, log io.Writer) error {
+	if issuer == "" || clientId == "" {
+		return errors.New("please provide an issuer, client ID and secret")
+	}
+	newAuth, err := clientAuth()
+	if err != nil {
+		return err
+	}
+	if err = outputFlags.Validate(); err != nil {
+		return err
+	}
+	if full && outputFlags.Format != output.FormatRaw {
+		return errors.New("--full can't be combined with --format")
+	}
+	// the secret is read last, so it isn't prompted for or consumed from stdin if a flag is invalid
+	clientSecret, err := secretFlags.Read(os.Stdin, log)
+	if err != nil {
+		return err
+	}
+	auth := newAuth(clientId, clientSecret)
+	client := &http.Client{Timeout: timeout}
+	config, err := oauth.Discover(ctx, client, issuer)
+	if err != nil {
+		return err
+	}
+	resp, err := oauth.ClientCredentials(ctx, client, config.TokenEndpoint, auth, oauth.WithProjectAudiences(scopes, projects))
+	if err != nil {
+		return fmt.Errorf("token request: %w", err)
+	}
+	if !full {
+		data, err := outputFlags.Render(output.FromResponse(resp))
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+	data, err := resp.IndentedJSON()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// clientAuth returns the constructor of the client authentication of --auth-method.
+func clientAuth() (func(clientID, clientSecret string) oauth.ClientAuth, error) {
+	switch authMethod {
+	case oauth.AuthMethodBasic:
+		return oauth.ClientSecretBasic, nil
+	case oauth.AuthMethodPost:
+		return oauth.ClientSecretPost, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth method %q, must be %s or %s", authMethod, oauth.AuthMethodBasic, oauth.AuthMethodPost)
+	}
+}
@@ -0,0 +1,145 @@
+package clientcredentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+func Test_clientCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":         issuer,
+			"token_endpoint": issuer + "/oauth/v2/token",
+		})
+	})
+	mux.HandleFunc("POST /oauth/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id, secret, ok := r.BasicAuth()
+		if ok {
+			id, _ = url.QueryUnescape(id)
+			secret, _ = url.QueryUnescape(secret)
+		} else {
+			id, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+		}
+		if r.PostFormValue("grant_type") != "client_credentials" || id != "client@project" || secret != "s:cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"scope":        r.PostFormValue("scope"),
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	tests := []struct {
+		name       string
+		secret     string
+		authMethod string
+		full       bool
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "unsupported auth method",
+			secret:     "s:cret",
+			authMethod: "private_key_jwt",
+			wantErr:    true,
+		},
+		{
+			name:       "wrong secret",
+			secret:     "secret",
+			authMethod: "client_secret_basic",
+			wantErr:    true,
+		},
+		{
+			name:       "client_secret_basic",
+			secret:     "s:cret",
+			authMethod: "client_secret_basic",
+			want:       "access\n",
+		},
+		{
+			name:       "client_secret_post",
+			secret:     "s:cret",
+			authMethod: "client_secret_post",
+			full:       true,
+			want: `{
+  "access_token": "access",
+  "scope": "openid urn:zitadel:iam:org:project:id:123:aud",
+  "token_type": "Bearer"
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientId = "client@project"
+			secretFlags.Value = tt.secret
+			authMethod = tt.authMethod
+			scopes = []string{"openid"}
+			projects = []string{"123"}
+			full = tt.full
+
+			var out bytes.Buffer
+			err := clientCredentials(context.Background(), &out, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func Test_clientCredentials_validateBeforeRead(t *testing.T) {
+	tests := []struct {
+		name       string
+		authMethod string
+		format     string
+		wantErr    string
+	}{
+		{
+			name:       "unsupported auth method",
+			authMethod: "private_key_jwt",
+			format:     output.FormatRaw,
+			wantErr:    "unsupported auth method",
+		},
+		{
+			name:       "unsupported format",
+			authMethod: oauth.AuthMethodBasic,
+			format:     "xml",
+			wantErr:    "xml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, clientId, authMethod, full = "https://zitadel.example.com", "client", tt.authMethod, false
+			outputFlags.Format = tt.format
+			// reading the secret would fail with another error
+			secretFlags.Value, secretFlags.From = "", "env:ZITADEL_TOOLS_TEST_UNSET"
+			t.Cleanup(func() { secretFlags.From, outputFlags.Format = "", output.FormatRaw })
+
+			err := clientCredentials(context.Background(), io.Discard, io.Discard)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
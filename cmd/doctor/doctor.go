@@ -0,0 +1,289 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+// Cmd represents the doctor command
+var Cmd = &cobra.Command{
+	Use:     "doctor",
+	Aliases: []string{"discover"},
+	Short:   "Check the discovery of an <issuer> and whether a <key file> can get a token",
+	Long: `Fetch the OpenID Connect discovery document and the keys of the issuer and check them for common mistakes,
+such as an issuer which differs from the discovered one by a trailing slash or a custom domain,
+or a local clock which differs from the one of ZITADEL.
+If a key file is given, it is used to request a token (serviceaccount) or to call the introspection endpoint (application),
+unless the issuer check failed.
+The command prints a checklist and exits with a non-zero code if any check fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doctor(cmd.Context(), cmd.OutOrStdout())
+	},
+}
+
+const (
+	// maxClockAhead is the tolerated difference of a local clock running ahead of ZITADEL,
+	// which would otherwise issue assertions in the future.
+	maxClockAhead = 2 * time.Second
+	// maxClockBehind is the tolerated difference of a local clock running behind ZITADEL,
+	// which shortens the lifetime of the assertions.
+	maxClockBehind = time.Minute
+)
+
+var (
+	issuer  string
+	keyPath string
+	format  string
+	timeout time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&issuer, "issuer", "", "issuer of your ZITADEL instance as it is passed to the other commands, e.g. as audience (e.g. https://<your domain>)")
+	Cmd.Flags().StringVar(&keyPath, "key", "", "key.json to check by requesting a token; a path, - for stdin, env:VAR or fd:N")
+	Cmd.Flags().StringVar(&format, "format", output.FormatText, "output format: text or json")
+	Cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+}
+
+type status string
+
+const (
+	statusPass status = "PASS"
+	statusWarn status = "WARN"
+	statusFail status = "FAIL"
+	statusInfo status = "INFO"
+)
+
+type result struct {
+	Check   string `json:"check"`
+	Status  status `json:"status"`
+	Message string `json:"message"`
+}
+
+func doctor(ctx context.Context, out io.Writer) error {
+	if issuer == "" {
+		return errors.New("please provide an issuer")
+	}
+	if err := output.CheckFormat(format, output.FormatText, output.FormatJSON); err != nil {
+		return err
+	}
+	var keyData []byte
+	if keyPath != "" {
+		var err error
+		if keyData, err = key.Read(keyPath, os.Stdin); err != nil {
+			return err
+		}
+	}
+	results := diagnose(ctx, &http.Client{Timeout: timeout}, issuer, keyData)
+
+	if format == output.FormatJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	} else {
+		for _, r := range results {
+			fmt.Fprintf(out, "[%s] %s: %s\n", r.Status, r.Check, r.Message)
+		}
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Status == statusFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
+
+// diagnose runs the checks against the issuer and, if keyData is not nil, the key.
+// Checks depending on a failed one are skipped.
+func diagnose(ctx context.Context, client *http.Client, issuer string, keyData []byte) []result {
+	var results []result
+	add := func(check string, s status, format string, args ...any) {
+		results = append(results, result{Check: check, Status: s, Message: fmt.Sprintf(format, args...)})
+	}
+
+	discoveryURL := oauth.DiscoveryURL(issuer)
+	config, local, date, err := fetchDiscovery(ctx, client, discoveryURL)
+	if err != nil {
+		add("discovery", statusFail, "%v", err)
+		return results
+	}
+	add("discovery", statusPass, "fetched %s", discoveryURL)
+	issuerResult := checkIssuer(issuer, config.Issuer)
+	results = append(results, issuerResult)
+	results = append(results, checkClock(local, date))
+
+	for _, endpoint := range []struct{ name, url string }{
+		{"token_endpoint", config.TokenEndpoint},
+		{"jwks_uri", config.JwksURI},
+	} {
+		if endpoint.url == "" {
+			add("endpoints", statusFail, "%s is missing", endpoint.name)
+			return results
+		}
+	}
+	keys, err := oauth.FetchKeys(ctx, client, config.JwksURI)
+	switch {
+	case err != nil:
+		add("jwks", statusFail, "%v", err)
+	case len(keys.Keys) == 0:
+		add("jwks", statusFail, "%s contains no keys", config.JwksURI)
+	default:
+		add("jwks", statusPass, "%s contains %d keys", config.JwksURI, len(keys.Keys))
+	}
+
+	grantTypes := make([]string, len(config.GrantTypesSupported))
+	for i, grantType := range config.GrantTypesSupported {
+		grantTypes[i] = string(grantType)
+	}
+	add("grant types", statusInfo, "%s", output.OrDash(strings.Join(grantTypes, ", ")))
+	authMethods := make([]string, len(config.TokenEndpointAuthMethodsSupported))
+	for i, method := range config.TokenEndpointAuthMethodsSupported {
+		authMethods[i] = string(method)
+	}
+	add("auth methods", statusInfo, "%s", output.OrDash(strings.Join(authMethods, ", ")))
+
+	switch {
+	case keyData == nil:
+	case issuerResult.Status == statusFail:
+		// the assertions would be signed for the wrong audience and rejected for that reason only
+		add("key", statusInfo, "skipped, fix the issuer first, as it is the audience of the assertions")
+	default:
+		results = append(results, checkKey(ctx, client, issuer, config, keyData)...)
+	}
+	return results
+}
+
+// fetchDiscovery fetches the discovery document and returns the local time in the middle of the request
+// with the time of the Date header of the response, which is zero if there is none.
+func fetchDiscovery(ctx context.Context, client *http.Client, discoveryURL string) (config *oidc.DiscoveryConfiguration, local, date time.Time, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, local, date, err
+	}
+	req.Header.Set("Accept", "application/json")
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, local, date, err
+	}
+	defer resp.Body.Close()
+	local = start.Add(time.Since(start) / 2)
+	if resp.StatusCode != http.StatusOK {
+		return nil, local, date, fmt.Errorf("%s returned status %d, check the scheme, domain and path of the issuer", discoveryURL, resp.StatusCode)
+	}
+	config = new(oidc.DiscoveryConfiguration)
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(config); err != nil {
+		return nil, local, date, fmt.Errorf("%s is not a discovery document: %w", discoveryURL, err)
+	}
+	date, _ = http.ParseTime(resp.Header.Get("Date"))
+	return config, local, date, nil
+}
+
+// checkIssuer compares the issuer passed by the user with the discovered one, which ZITADEL compares exactly.
+func checkIssuer(passed, discovered string) result {
+	r := result{Check: "issuer", Status: statusFail}
+	passedURL, err1 := url.Parse(passed)
+	discoveredURL, err2 := url.Parse(discovered)
+	switch {
+	case passed == discovered:
+		r.Status, r.Message = statusPass, fmt.Sprintf("%q matches the discovered issuer", passed)
+	case err1 != nil || err2 != nil:
+		r.Message = fmt.Sprintf("%q differs from the discovered issuer %q", passed, discovered)
+	case strings.TrimSuffix(passed, "/") == strings.TrimSuffix(discovered, "/"):
+		r.Message = fmt.Sprintf("%q differs from the discovered issuer %q by a trailing slash, use %q as issuer and audience", passed, discovered, discovered)
+	case passedURL.Host != discoveredURL.Host:
+		r.Message = fmt.Sprintf("the instance is served as %q, not %q (e.g. a custom domain or proxy); use %q as issuer and audience", discovered, passed, discovered)
+	case passedURL.Scheme != discoveredURL.Scheme:
+		r.Message = fmt.Sprintf("the scheme of %q differs from the discovered issuer %q, check the external secure setting of ZITADEL", passed, discovered)
+	default:
+		r.Message = fmt.Sprintf("%q differs from the discovered issuer %q, use %q as issuer and audience", passed, discovered, discovered)
+	}
+	return r
+}
+
+// checkClock reports the offset of the local clock from the clock of ZITADEL given by the Date header.
+func checkClock(local, date time.Time) result {
+	if date.IsZero() {
+		return result{"clock", statusWarn, "the response has no Date header, the clock can't be compared"}
+	}
+	// the Date header has a precision of one second
+	offset := local.Sub(date.Add(500 * time.Millisecond))
+	rounded := offset.Abs().Round(time.Second)
+	switch {
+	case offset > maxClockAhead:
+		return result{"clock", statusFail, fmt.Sprintf("the local clock is %s ahead of ZITADEL, so assertions are issued in the future; synchronize the clock or use --skew=%s", rounded, rounded+time.Second)}
+	case offset < -maxClockBehind:
+		return result{"clock", statusWarn, fmt.Sprintf("the local clock is %s behind ZITADEL, which shortens the lifetime of assertions", rounded)}
+	default:
+		return result{"clock", statusPass, fmt.Sprintf("the local clock differs by %s from ZITADEL", rounded)}
+	}
+}
+
+// checkKey validates the key file and uses it to request a token (serviceaccount)
+// or to authenticate at the introspection endpoint (application).
+func checkKey(ctx context.Context, client *http.Client, issuer string, config *oidc.DiscoveryConfiguration, data []byte) []result {
+	privateKey, err := key.LoadPrivateKey(data)
+	if err == nil && privateKey.File == nil {
+		err = errors.New("not a ZITADEL key file")
+	}
+	if err == nil {
+		err = privateKey.File.Validate()
+	}
+	if err != nil {
+		return []result{{"key", statusFail, strings.ReplaceAll(err.Error(), "\n", "; ")}}
+	}
+	file := privateKey.File
+	if expiration := file.ExpirationDate; !expiration.IsZero() && expiration.Before(time.Now()) {
+		return []result{{"key", statusFail, fmt.Sprintf("key %q expired on %s", file.KeyID, expiration.Format(time.RFC3339))}}
+	}
+	results := []result{{"key", statusPass, fmt.Sprintf("%s key %q of %q is valid", file.Type, file.KeyID, file.Subject())}}
+
+	switch file.Type {
+	case key.TypeServiceAccount:
+		if len(config.GrantTypesSupported) > 0 && !slices.Contains(config.GrantTypesSupported, oidc.GrantTypeBearer) {
+			return append(results, result{"token", statusFail, "the JWT profile grant is not supported by the issuer"})
+		}
+		jwt, err := assertion.FromKey(data, "", "", &assertion.Options{Audience: []string{issuer}, Lifetime: time.Minute})
+		if err == nil {
+			_, err = oauth.JWTProfile(ctx, client, config.TokenEndpoint, jwt, []string{oidc.ScopeOpenID})
+		}
+		if err != nil {
+			return append(results, result{"token", statusFail, fmt.Sprintf("the key can't get a token: %v", err)})
+		}
+		return append(results, result{"token", statusPass, "the key received a token with the JWT profile grant"})
+	default:
+		if config.IntrospectionEndpoint == "" {
+			return append(results, result{"introspection", statusFail, "introspection_endpoint is missing"})
+		}
+		clientAssertion, err := assertion.ClientAssertion(data, "", issuer)
+		if err == nil {
+			_, err = oauth.Introspect(ctx, client, config.IntrospectionEndpoint, "zitadel-tools-doctor", oauth.PrivateKeyJWT(clientAssertion))
+		}
+		if err != nil {
+			return append(results, result{"introspection", statusFail, fmt.Sprintf("the key can't authenticate at the introspection endpoint: %v", err)})
+		}
+		return append(results, result{"introspection", statusPass, "the key authenticated at the introspection endpoint with private_key_jwt"})
+	}
+}
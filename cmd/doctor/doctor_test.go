@@ -0,0 +1,110 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+func Test_doctor(t *testing.T) {
+	dir := t.TempDir()
+	registered := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user"})
+	registeredKey := keytest.Write(t, dir, "registered.json", registered)
+	unregisteredKey := keytest.Write(t, dir, "unregistered.json", keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user"}))
+
+	op := mockoptest.Start(t, mockop.Config{Keys: [][]byte{registered}})
+	clock := func(offset time.Duration) string {
+		return mockoptest.Start(t, mockop.Config{Now: func() time.Time { return time.Now().Add(offset) }}).Issuer()
+	}
+	// an OP behind a proxy, which is served with another issuer than the URL it is reached by
+	proxied, err := mockop.New("https://auth.example.com", mockop.Config{})
+	require.NoError(t, err)
+	proxy := httptest.NewServer(proxied)
+	defer proxy.Close()
+
+	tests := []struct {
+		name    string
+		issuer  string
+		keyPath string
+		want    map[string]status
+		wantErr bool
+	}{
+		{
+			name:    "healthy",
+			issuer:  op.Issuer(),
+			keyPath: registeredKey,
+			want:    map[string]status{"discovery": statusPass, "issuer": statusPass, "clock": statusPass, "jwks": statusPass, "key": statusPass, "token": statusPass},
+		},
+		{
+			name:    "trailing slash",
+			issuer:  op.Issuer() + "/",
+			keyPath: registeredKey,
+			want:    map[string]status{"discovery": statusPass, "issuer": statusFail, "key": statusInfo, "token": ""},
+			wantErr: true,
+		},
+		{
+			name:    "custom domain",
+			issuer:  proxy.URL,
+			want:    map[string]status{"issuer": statusFail},
+			wantErr: true,
+		},
+		{
+			name:    "wrong path",
+			issuer:  op.Issuer() + "/oauth/v2",
+			want:    map[string]status{"discovery": statusFail},
+			wantErr: true,
+		},
+		{
+			name:    "clock ahead",
+			issuer:  clock(-time.Minute),
+			want:    map[string]status{"clock": statusFail},
+			wantErr: true,
+		},
+		{
+			name:   "clock behind",
+			issuer: clock(5 * time.Minute),
+			want:   map[string]status{"clock": statusWarn},
+		},
+		{
+			name:    "unregistered key",
+			issuer:  op.Issuer(),
+			keyPath: unregisteredKey,
+			want:    map[string]status{"key": statusPass, "token": statusFail},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, keyPath, format, timeout = tt.issuer, tt.keyPath, output.FormatJSON, 10*time.Second
+
+			var out bytes.Buffer
+			err := doctor(context.Background(), &out)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			var results []result
+			require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+			got := make(map[string]status, len(results))
+			for _, r := range results {
+				got[r.Check] = r.Status
+			}
+			for check, want := range tt.want {
+				assert.Equal(t, want, got[check], check)
+			}
+		})
+	}
+}
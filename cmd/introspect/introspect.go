@@ -0,0 +1,141 @@
+package introspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/clientauth"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/internal/ref"
+)
+
+// Cmd represents the introspect command
+var Cmd = &cobra.Command{
+	Use:   "introspect [<token> | -]",
+	Short: "Ask ZITADEL whether a <token> is active and show its scopes, roles and expiry",
+	Long: `Call the introspection endpoint of the issuer as an API application to debug rejected tokens.
+The API is authenticated with an application key (private_key_jwt) or with its client ID and secret (basic auth).
+The token is read from the first argument or from standard input if it is omitted or "-".
+The command exits with a non-zero code if the token is not active.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return introspect(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr(), args)
+	},
+}
+
+var (
+	issuer      string
+	clientFlags clientauth.Flags
+	format      string
+	timeout     time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&issuer, "issuer", "", "issuer of your ZITADEL instance, used to discover the introspection endpoint (e.g. https://<your domain>)")
+	clientFlags.Register(Cmd.Flags(), "key", "of the API")
+	Cmd.Flags().StringVar(&format, "format", output.FormatTable, "output format: table or json")
+	Cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+}
+
+func introspect(ctx context.Context, in io.Reader, out, log io.Writer, args []string) error {
+	if issuer == "" {
+		return errors.New("please provide an issuer")
+	}
+	if err := output.CheckFormat(format, output.FormatTable, output.FormatJSON); err != nil {
+		return err
+	}
+	if err := ref.CheckStdin(append(clientFlags.Refs(), output.TokenRef(args, "token"))...); err != nil {
+		return err
+	}
+	auth, err := clientFlags.Auth(issuer, log)
+	if err != nil {
+		return err
+	}
+	token, err := output.ReadToken(in, args, "token")
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+	config, err := oauth.Discover(ctx, client, issuer)
+	if err != nil {
+		return err
+	}
+	if config.IntrospectionEndpoint == "" {
+		return errors.New("discovery: introspection_endpoint is missing")
+	}
+	result, err := oauth.Introspect(ctx, client, config.IntrospectionEndpoint, token, auth)
+	if err != nil {
+		return fmt.Errorf("introspection request: %w", err)
+	}
+
+	if format == output.FormatJSON {
+		data, err := result.IndentedJSON()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	} else {
+		printIntrospection(out, result, time.Now())
+	}
+	if !result.Active {
+		return errors.New("the token is not active")
+	}
+	return nil
+}
+
+func printIntrospection(out io.Writer, result *oauth.Introspection, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "active:\t%t\n", result.Active)
+	if !result.Active {
+		return
+	}
+	subject := result.Subject
+	if result.Username != "" {
+		subject += " (" + result.Username + ")"
+	}
+	fmt.Fprintf(w, "subject:\t%s\n", output.OrDash(subject))
+	fmt.Fprintf(w, "client:\t%s\n", output.OrDash(result.ClientID))
+	fmt.Fprintf(w, "issuer:\t%s\n", output.OrDash(result.Issuer))
+	fmt.Fprintf(w, "audience:\t%s\n", output.OrDash(strings.Join(result.Audience, ", ")))
+	fmt.Fprintf(w, "scopes:\t%s\n", output.OrDash(strings.Join(result.Scope, " ")))
+	if len(result.Roles) == 0 {
+		fmt.Fprintln(w, "roles:\t-")
+	}
+	for i, role := range slices.Sorted(maps.Keys(result.Roles)) {
+		label := ""
+		if i == 0 {
+			label = "roles:"
+		}
+		var orgs []string
+		for _, id := range slices.Sorted(maps.Keys(result.Roles[role])) {
+			orgs = append(orgs, fmt.Sprintf("%s (%s)", id, result.Roles[role][id]))
+		}
+		fmt.Fprintf(w, "%s\t%s in %s\n", label, role, strings.Join(orgs, ", "))
+	}
+	fmt.Fprintf(w, "issued:\t%s\n", formatTime(result.IssuedAt, now))
+	fmt.Fprintf(w, "expires:\t%s\n", formatTime(result.Expiration, now))
+}
+
+func formatTime(ts oidc.Time, now time.Time) string {
+	if ts == 0 {
+		return "-"
+	}
+	t := ts.AsTime()
+	if t.After(now) {
+		return fmt.Sprintf("%s (in %s)", t.UTC().Format(time.RFC3339), t.Sub(now).Round(time.Second))
+	}
+	return fmt.Sprintf("%s (%s ago)", t.UTC().Format(time.RFC3339), now.Sub(t).Round(time.Second))
+}
@@ -0,0 +1,113 @@
+package introspect
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+func Test_introspect(t *testing.T) {
+	keyFile := keytest.File(t, key.File{Type: key.TypeApplication, KeyID: "kid", ClientID: "api", AppID: "app"})
+	appKeyPath := keytest.Write(t, t.TempDir(), "key.json", keyFile)
+	op := mockoptest.Start(t, mockop.Config{
+		Keys:    [][]byte{keyFile},
+		Clients: map[string]string{"api": "secret"},
+		Roles:   map[string]map[string]string{"admin": {"1": "acme.example.com"}},
+	})
+	issuer = op.Issuer()
+	active, err := op.Issue("user", "client", []string{"openid", "profile", oauth.ScopeAllProjectRoles})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		keyPath   string
+		clientID  string
+		secret    string
+		format    string
+		token     string
+		wantLines []string
+		wantErr   bool
+	}{
+		{
+			name:    "no authentication",
+			format:  output.FormatTable,
+			token:   active.AccessToken,
+			wantErr: true,
+		},
+		{
+			name:     "wrong secret",
+			clientID: "api",
+			secret:   "wrong",
+			format:   output.FormatTable,
+			token:    active.AccessToken,
+			wantErr:  true,
+		},
+		{
+			name:      "inactive",
+			keyPath:   appKeyPath,
+			format:    output.FormatTable,
+			token:     "expired",
+			wantLines: []string{"active:  false"},
+			wantErr:   true,
+		},
+		{
+			name:    "active with key",
+			keyPath: appKeyPath,
+			format:  output.FormatTable,
+			token:   active.AccessToken,
+			wantLines: []string{
+				"active:    true",
+				"subject:   user",
+				"scopes:    openid profile urn:zitadel:iam:org:projects:roles",
+				"roles:     admin in 1 (acme.example.com)",
+			},
+		},
+		{
+			name:      "active with secret",
+			clientID:  "api",
+			secret:    "secret",
+			format:    output.FormatJSON,
+			token:     active.AccessToken,
+			wantLines: []string{`  "active": true,`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientFlags.KeyPath, clientFlags.ClientID, format, timeout = tt.keyPath, tt.clientID, tt.format, 10*time.Second
+			clientFlags.Secret.Value, clientFlags.Secret.From = tt.secret, ""
+
+			var out bytes.Buffer
+			err := introspect(context.Background(), strings.NewReader(tt.token), &out, io.Discard, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			lines := strings.Split(out.String(), "\n")
+			for _, line := range tt.wantLines {
+				assert.Contains(t, lines, line)
+			}
+		})
+	}
+}
+
+func Test_introspect_stdin(t *testing.T) {
+	issuer, clientFlags.KeyPath, clientFlags.ClientID, format = "https://zitadel.example.com", "-", "", output.FormatTable
+	clientFlags.Secret.Value, clientFlags.Secret.From = "", ""
+
+	err := introspect(context.Background(), strings.NewReader("active"), io.Discard, io.Discard, nil)
+	assert.ErrorContains(t, err, "- is passed 2 times (--key, the token)")
+}
@@ -0,0 +1,269 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/spf13/cobra"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [<jwt> | -]",
+	Short: "Decode a <jwt> and verify its signature and claims",
+	Long: `Decode the header and claims of a JWT and check them for common mistakes.
+The JWT is read from the first argument or from standard input if it is omitted or "-".
+When a key is provided, the signature is verified against its public part.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return inspect(cmd.InOrStdin(), cmd.OutOrStdout(), args)
+	},
+}
+
+var (
+	inspectKeyPath  string
+	inspectAudience string
+)
+
+func init() {
+	inspectCmd.Flags().StringVar(&inspectKeyPath, "key", "", "key.json, PEM key, JWK or JWKS used to verify the signature; a path, - for stdin, env:VAR or fd:N")
+	inspectCmd.Flags().StringVar(&inspectAudience, "audience", "", "expected audience, which is the issuer of your ZITADEL instance (e.g. https://<your domain>)")
+}
+
+type decodedToken struct {
+	header    json.RawMessage
+	payload   json.RawMessage
+	keyID     string
+	algorithm string
+	claims    tokenClaims
+}
+
+type tokenClaims struct {
+	Issuer     string        `json:"iss"`
+	Subject    string        `json:"sub"`
+	Audience   oidc.Audience `json:"aud"`
+	IssuedAt   oidc.Time     `json:"iat"`
+	NotBefore  oidc.Time     `json:"nbf"`
+	Expiration oidc.Time     `json:"exp"`
+}
+
+type level string
+
+const (
+	levelOK      level = "OK"
+	levelWarning level = "WARN"
+	levelError   level = "FAIL"
+)
+
+type finding struct {
+	level   level
+	message string
+}
+
+func inspect(in io.Reader, out io.Writer, args []string) error {
+	if inspectKeyPath == "-" && (len(args) == 0 || args[0] == "-") {
+		return errors.New("the jwt and --key can't both be read from stdin, pass one of them as argument or file")
+	}
+	token, err := output.ReadToken(in, args, "jwt")
+	if err != nil {
+		return err
+	}
+	decoded, err := decodeToken(token)
+	if err != nil {
+		return err
+	}
+	var findings []finding
+	var keyFile *key.File
+	if inspectKeyPath != "" {
+		data, err := key.Read(inspectKeyPath, in)
+		if err != nil {
+			return err
+		}
+		keys, err := key.PublicKeys(data)
+		if err != nil {
+			return err
+		}
+		if file, err := key.ParseFile(data); err == nil && file.Key != "" {
+			keyFile = file
+		}
+		findings = append(findings, verifySignature(token, keys))
+	}
+	findings = append(findings, checkClaims(decoded, inspectAudience, keyFile, time.Now())...)
+
+	printToken(out, decoded, findings, time.Now())
+	for _, f := range findings {
+		if f.level == levelError {
+			return errors.New("the JWT has errors")
+		}
+	}
+	return nil
+}
+
+func decodeToken(token string) (*decodedToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed jwt: expected 3 parts, got %d", len(parts))
+	}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed jwt header: %w", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed jwt payload: %w", err)
+	}
+	decoded := &decodedToken{
+		header:  header,
+		payload: payload,
+	}
+	h := new(struct {
+		KeyID     string `json:"kid"`
+		Algorithm string `json:"alg"`
+	})
+	if err = json.Unmarshal(header, h); err != nil {
+		return nil, fmt.Errorf("malformed jwt header: %w", err)
+	}
+	decoded.keyID, decoded.algorithm = h.KeyID, h.Algorithm
+	if err = json.Unmarshal(payload, &decoded.claims); err != nil {
+		return nil, fmt.Errorf("malformed jwt claims: %w", err)
+	}
+	return decoded, nil
+}
+
+func verifySignature(token string, keys []jose.JSONWebKey) finding {
+	sig, err := jose.ParseSigned(token, key.SignatureAlgorithms)
+	if err != nil {
+		return finding{levelError, fmt.Sprintf("signature cannot be verified: %v", err)}
+	}
+	kid := sig.Signatures[0].Header.KeyID
+	for _, k := range keys {
+		if kid != "" && k.KeyID != "" && k.KeyID != kid {
+			continue
+		}
+		if _, err = sig.Verify(k); err == nil {
+			return finding{levelOK, fmt.Sprintf("signature verified with key %q", k.KeyID)}
+		}
+	}
+	return finding{levelError, "signature does not match any of the provided keys"}
+}
+
+// checkClaims looks for mistakes which lead ZITADEL to reject a JWT profile assertion.
+func checkClaims(token *decodedToken, audience string, keyFile *key.File, now time.Time) []finding {
+	var findings []finding
+	claims := token.claims
+
+	if token.keyID == "" {
+		findings = append(findings, finding{levelWarning, "header has no kid, ZITADEL needs it to find the public key"})
+	}
+	if keyFile != nil {
+		if token.keyID != keyFile.KeyID {
+			findings = append(findings, finding{levelError, fmt.Sprintf("kid %q does not match the keyId %q of the key file", token.keyID, keyFile.KeyID)})
+		}
+		if subject := keyFile.Subject(); claims.Subject != subject {
+			findings = append(findings, finding{levelError, fmt.Sprintf("sub %q does not match the %s key owner %q", claims.Subject, keyFile.Type, subject)})
+		}
+		switch expiration := keyFile.ExpirationDate; {
+		case expiration.IsZero():
+		case expiration.Before(now):
+			findings = append(findings, finding{levelError, fmt.Sprintf("key %q expired on %s", keyFile.KeyID, expiration.Format(time.RFC3339))})
+		default:
+			findings = append(findings, finding{levelOK, fmt.Sprintf("key %q is valid until %s", keyFile.KeyID, expiration.Format(time.RFC3339))})
+		}
+	}
+	if claims.Issuer != claims.Subject {
+		findings = append(findings, finding{levelWarning, fmt.Sprintf("iss %q and sub %q differ, ZITADEL expects both to be the user or client ID", claims.Issuer, claims.Subject)})
+	}
+	findings = append(findings, checkAudience(claims.Audience, audience)...)
+
+	switch exp := claims.Expiration.AsTime(); {
+	case exp.IsZero():
+		findings = append(findings, finding{levelError, "exp claim is missing"})
+	case exp.Before(now):
+		findings = append(findings, finding{levelError, fmt.Sprintf("token expired %s ago", now.Sub(exp).Round(time.Second))})
+	default:
+		findings = append(findings, finding{levelOK, fmt.Sprintf("token expires in %s", exp.Sub(now).Round(time.Second))})
+	}
+	if iat := claims.IssuedAt.AsTime(); iat.After(now) {
+		findings = append(findings, finding{levelError, fmt.Sprintf("token is issued %s in the future, check the clock of the signing machine", iat.Sub(now).Round(time.Second))})
+	}
+	if nbf := claims.NotBefore.AsTime(); nbf.After(now) {
+		findings = append(findings, finding{levelError, fmt.Sprintf("token is not valid before %s", nbf.Format(time.RFC3339))})
+	}
+	return findings
+}
+
+func checkAudience(got oidc.Audience, want string) []finding {
+	if len(got) == 0 {
+		return []finding{{levelError, "aud claim is missing"}}
+	}
+	if want == "" {
+		return nil
+	}
+	if slices.Contains(got, want) {
+		return []finding{{levelOK, fmt.Sprintf("audience contains %q", want)}}
+	}
+	trimmed := strings.TrimSuffix(want, "/")
+	for _, aud := range got {
+		switch {
+		case strings.TrimSuffix(aud, "/") == trimmed:
+			return []finding{{levelError, fmt.Sprintf("audience %q differs from the issuer %q by a trailing slash, ZITADEL compares them exactly", aud, want)}}
+		case strings.HasPrefix(aud, trimmed+"/"):
+			return []finding{{levelError, fmt.Sprintf("audience %q is an endpoint, use the issuer %q instead", aud, want)}}
+		}
+	}
+	return []finding{{levelError, fmt.Sprintf("audience %v does not contain the issuer %q", []string(got), want)}}
+}
+
+func printToken(w io.Writer, token *decodedToken, findings []finding, now time.Time) {
+	fmt.Fprintln(w, "Header:")
+	fmt.Fprintln(w, indentJSON(token.header))
+	fmt.Fprintln(w, "Claims:")
+	fmt.Fprintln(w, indentJSON(token.payload))
+	fmt.Fprintln(w)
+
+	claims := token.claims
+	fmt.Fprintf(w, "iss: %s\n", claims.Issuer)
+	fmt.Fprintf(w, "sub: %s\n", claims.Subject)
+	fmt.Fprintf(w, "aud: %s\n", strings.Join(claims.Audience, ", "))
+	fmt.Fprintf(w, "iat: %s\n", formatTime(claims.IssuedAt, now))
+	if claims.NotBefore != 0 {
+		fmt.Fprintf(w, "nbf: %s\n", formatTime(claims.NotBefore, now))
+	}
+	fmt.Fprintf(w, "exp: %s\n", formatTime(claims.Expiration, now))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Checks:")
+	for _, f := range findings {
+		fmt.Fprintf(w, "  [%s] %s\n", f.level, f.message)
+	}
+}
+
+func indentJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+func formatTime(ts oidc.Time, now time.Time) string {
+	if ts == 0 {
+		return "-"
+	}
+	t := ts.AsTime()
+	if t.After(now) {
+		return fmt.Sprintf("%s (in %s)", t.UTC().Format(time.RFC3339), t.Sub(now).Round(time.Second))
+	}
+	return fmt.Sprintf("%s (%s ago)", t.UTC().Format(time.RFC3339), now.Sub(t).Round(time.Second))
+}
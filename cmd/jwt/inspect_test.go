@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+)
+
+func Test_checkClaims(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	valid := tokenClaims{
+		Issuer:     "user",
+		Subject:    "user",
+		Audience:   oidc.Audience{"https://example.zitadel.cloud"},
+		IssuedAt:   oidc.FromTime(now.Add(-time.Minute)),
+		Expiration: oidc.FromTime(now.Add(time.Hour)),
+	}
+	keyFile := &key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user", ExpirationDate: now.Add(24 * time.Hour)}
+
+	tests := []struct {
+		name     string
+		modify   func(*decodedToken)
+		audience string
+		keyFile  *key.File
+		want     []string
+	}{
+		{
+			name:     "valid",
+			audience: "https://example.zitadel.cloud",
+			keyFile:  keyFile,
+		},
+		{
+			name:     "trailing slash",
+			audience: "https://example.zitadel.cloud/",
+			want:     []string{"trailing slash"},
+		},
+		{
+			name: "token endpoint as audience",
+			modify: func(d *decodedToken) {
+				d.claims.Audience = oidc.Audience{"https://example.zitadel.cloud/oauth/v2/token"}
+			},
+			audience: "https://example.zitadel.cloud",
+			want:     []string{"is an endpoint"},
+		},
+		{
+			name:     "other audience",
+			audience: "https://other.zitadel.cloud",
+			want:     []string{"does not contain the issuer"},
+		},
+		{
+			name:   "expired",
+			modify: func(d *decodedToken) { d.claims.Expiration = oidc.FromTime(now.Add(-time.Minute)) },
+			want:   []string{"token expired 1m0s ago"},
+		},
+		{
+			name:   "issued in the future",
+			modify: func(d *decodedToken) { d.claims.IssuedAt = oidc.FromTime(now.Add(time.Minute)) },
+			want:   []string{"issued 1m0s in the future"},
+		},
+		{
+			name:    "expired key",
+			keyFile: &key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user", ExpirationDate: now.Add(-time.Hour)},
+			want:    []string{"expired on 2024-01-01T11:00:00Z"},
+		},
+		{
+			name:    "wrong key",
+			keyFile: &key.File{Type: key.TypeApplication, KeyID: "other", ClientID: "client"},
+			want:    []string{`kid "kid" does not match`, `sub "user" does not match the application key owner "client"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &decodedToken{keyID: "kid", claims: valid}
+			if tt.modify != nil {
+				tt.modify(token)
+			}
+			var errs []string
+			for _, f := range checkClaims(token, tt.audience, tt.keyFile, now) {
+				if f.level == levelError {
+					errs = append(errs, f.message)
+				}
+			}
+			require.Len(t, errs, len(tt.want), errs)
+			for i, want := range tt.want {
+				assert.Contains(t, errs[i], want)
+			}
+		})
+	}
+}
+
+func Test_inspect(t *testing.T) {
+	dir := t.TempDir()
+	data := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user"})
+	signingKey := keytest.Write(t, dir, "key.json", data)
+	otherKey := keytest.Write(t, dir, "other.json", keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user"}))
+
+	const audience = "https://example.zitadel.cloud"
+	token, err := assertion.FromKey(data, "", "", &assertion.Options{Audience: []string{audience}, Lifetime: time.Hour})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		key        string
+		wantErr    bool
+		wantOutput string
+	}{
+		{
+			name:    "malformed",
+			args:    []string{"foo"},
+			wantErr: true,
+		},
+		{
+			name:       "verified from argument",
+			args:       []string{token},
+			key:        signingKey,
+			wantOutput: `[OK] signature verified with key "kid"`,
+		},
+		{
+			name:       "decoded from stdin",
+			stdin:      token + "\n",
+			wantOutput: "sub: user",
+		},
+		{
+			name:    "jwt and key from stdin",
+			stdin:   token + "\n",
+			key:     "-",
+			wantErr: true,
+		},
+		{
+			name:       "wrong key",
+			args:       []string{token},
+			key:        otherKey,
+			wantErr:    true,
+			wantOutput: "[FAIL] signature does not match",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspectKeyPath = tt.key
+			inspectAudience = audience
+			var out bytes.Buffer
+			err := inspect(strings.NewReader(tt.stdin), &out, tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Contains(t, out.String(), tt.wantOutput)
+		})
+	}
+}
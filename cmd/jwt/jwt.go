@@ -0,0 +1,16 @@
+package jwt
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// GroupCmd represents the jwt command, which groups the subcommands minting and checking JWTs
+var GroupCmd = &cobra.Command{
+	Use:   "jwt",
+	Short: "Mint, inspect and verify JWTs",
+}
+
+func init() {
+	GroupCmd.AddCommand(inspectCmd)
+	GroupCmd.AddCommand(mintCmd)
+}
@@ -0,0 +1,285 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+var mintCmd = &cobra.Command{
+	Use:   "mint",
+	Short: "Mint an ID token signed by a local <key>, e.g. for a JWT identity provider",
+	Long: `Sign an ID token with arbitrary claims with a local private key and export the matching JWKS,
+e.g. to test the JWT identity provider of ZITADEL, which validates externally signed tokens against a keys endpoint.
+
+The claims are merged from, in increasing precedence:
+  - the JSON object of --template, a Go template executed with the fields
+    .Issuer, .Subject, .Email, .Audience, .IssuedAt and .Expiry (Unix seconds)
+    and the function json, which encodes a value as JSON (e.g. "email": {{json .Email}})
+  - the iss, sub, aud, email, iat, nbf and exp claims of the flags
+  - the JSON object of --claims-file and the --claim pairs
+
+A negative --lifetime mints a token which expired that long ago, after being valid for as long.
+The JWKS is written to --jwks or served on http://<--serve>/keys until interrupted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return mint(ctx, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+const keysPath = "/keys"
+
+var (
+	mintFlags         assertion.Flags
+	mintOutputFlags   output.Flags
+	mintIssuer        string
+	mintSubject       string
+	mintEmail         string
+	mintEmailVerified bool
+	mintTemplate      string
+	mintOutputPath    string
+	mintJWKSPath      string
+	mintServe         string
+)
+
+func init() {
+	mintFlags.RegisterKey(mintCmd.Flags())
+	mintFlags.RegisterClaims(mintCmd.Flags())
+	mintOutputFlags.Register(mintCmd.Flags())
+	mintCmd.Flags().StringVar(&mintIssuer, "issuer", "", "iss claim, which must match the issuer configured for the identity provider")
+	mintCmd.Flags().StringVar(&mintSubject, "subject", "", "sub claim, the ID of the user at the identity provider")
+	mintCmd.Flags().StringVar(&mintEmail, "email", "", "email claim")
+	mintCmd.Flags().BoolVar(&mintEmailVerified, "email-verified", false, "set the email_verified claim to true, requires --email")
+	mintCmd.Flags().StringVar(&mintTemplate, "template", "", "path to a JSON object with claims, executed as Go template")
+	mintCmd.Flags().StringVar(&mintOutputPath, "output", "", "path where the token will be saved; will print to stdout if empty")
+	mintCmd.Flags().StringVar(&mintJWKSPath, "jwks", "", "path where the JWKS with the public key will be saved")
+	mintCmd.Flags().StringVar(&mintServe, "serve", "", "address to serve the JWKS on at "+keysPath+" after minting (e.g. 127.0.0.1:8089)")
+}
+
+// mintTemplateData are the fields available in the claims template.
+type mintTemplateData struct {
+	Issuer   string
+	Subject  string
+	Email    string
+	Audience []string
+	IssuedAt int64
+	Expiry   int64
+}
+
+func mint(ctx context.Context, out, log io.Writer) error {
+	switch {
+	case mintFlags.KeyPath == "" || mintIssuer == "" || mintSubject == "":
+		return errors.New("please provide at least a key, issuer and subject param")
+	case mintFlags.Lifetime == 0:
+		return errors.New("lifetime must not be zero")
+	case mintFlags.Skew < 0:
+		return fmt.Errorf("skew must not be negative, got %s", mintFlags.Skew)
+	case mintEmailVerified && mintEmail == "":
+		return errors.New("--email-verified requires --email")
+	}
+	alg, err := key.ParseAlgorithm(mintFlags.Algorithm)
+	if err != nil {
+		return err
+	}
+	if err = mintOutputFlags.Validate(); err != nil {
+		return err
+	}
+	data, err := key.Read(mintFlags.KeyPath, os.Stdin)
+	if err != nil {
+		return err
+	}
+	signer, jwk, err := mintSigner(data, alg)
+	if err != nil {
+		return err
+	}
+	claims, err := mintClaimSet(time.Now())
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return fmt.Errorf("marshal claims: %w", err)
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		return fmt.Errorf("sign token: %w", err)
+	}
+	token, err := signed.CompactSerialize()
+	if err != nil {
+		return err
+	}
+	rendered, err := mintOutputFlags.Render(output.NewToken(token, 0))
+	if err != nil {
+		return err
+	}
+	if mintOutputPath != "" {
+		err = output.WriteFile(mintOutputPath, rendered, 0600)
+	} else {
+		_, err = out.Write(rendered)
+	}
+	if err != nil {
+		return err
+	}
+	jwks := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{jwk}}
+	if mintJWKSPath != "" {
+		data, err := json.MarshalIndent(jwks, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = output.WriteFile(mintJWKSPath, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	if mintServe == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", mintServe)
+	if err != nil {
+		return err
+	}
+	return serveJWKS(ctx, listener, log, jwks)
+}
+
+// mintSigner returns the signer of the private key in data and the public JWK to verify its signatures.
+// The kid of both is taken from the key like [key.JWK] does, the algorithm from the key type if alg is empty.
+func mintSigner(data []byte, alg jose.SignatureAlgorithm) (jose.Signer, jose.JSONWebKey, error) {
+	privateKey, err := key.LoadPrivateKey(data)
+	if err != nil {
+		return nil, jose.JSONWebKey{}, err
+	}
+	jwk, err := key.JWK(data, false)
+	if err != nil {
+		return nil, jose.JSONWebKey{}, err
+	}
+	if alg == "" {
+		alg = jose.SignatureAlgorithm(jwk.Algorithm)
+	}
+	signer, err := key.NewSigner(privateKey.Signer, jwk.KeyID, alg)
+	if err != nil {
+		return nil, jose.JSONWebKey{}, err
+	}
+	jwk.Algorithm = string(alg)
+	return signer, jwk, nil
+}
+
+// mintClaimSet merges the claims of the template, the flags and the claims file and pairs.
+func mintClaimSet(now time.Time) (map[string]any, error) {
+	issuedAt, expiry := mintTimes(now)
+	templateData := &mintTemplateData{
+		Issuer:   mintIssuer,
+		Subject:  mintSubject,
+		Email:    mintEmail,
+		Audience: mintFlags.Audience,
+		IssuedAt: issuedAt.Unix(),
+		Expiry:   expiry.Unix(),
+	}
+	claims := make(map[string]any)
+	if mintTemplate != "" {
+		rendered, err := renderTemplate(mintTemplate, templateData)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(rendered, &claims); err != nil {
+			return nil, fmt.Errorf("template %s: %w", mintTemplate, err)
+		}
+		if claims == nil {
+			claims = make(map[string]any)
+		}
+	}
+	claims["iss"] = templateData.Issuer
+	claims["sub"] = templateData.Subject
+	claims["iat"] = templateData.IssuedAt
+	claims["exp"] = templateData.Expiry
+	if mintFlags.Skew > 0 {
+		claims["nbf"] = templateData.IssuedAt
+	}
+	if len(mintFlags.Audience) > 0 {
+		claims["aud"] = mintFlags.Audience
+	}
+	if mintEmail != "" {
+		claims["email"] = mintEmail
+	}
+	if mintEmailVerified {
+		claims["email_verified"] = true
+	}
+	pairs, err := assertion.ParseClaims(mintFlags.Claims, mintFlags.ClaimsFile)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(claims, pairs)
+	return claims, nil
+}
+
+// mintTimes returns the iat and exp of a token minted at now, with iat backdated by the skew.
+// A negative lifetime moves both back, so the token expired that long ago after being valid for as long.
+func mintTimes(now time.Time) (issuedAt, expiry time.Time) {
+	lifetime := mintFlags.Lifetime
+	if lifetime < 0 {
+		now, lifetime = now.Add(2*lifetime), -lifetime
+	}
+	return now.Add(-mintFlags.Skew), now.Add(lifetime)
+}
+
+func renderTemplate(path string, data *mintTemplateData) ([]byte, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	tmpl, err := template.New(path).Option("missingkey=error").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+	}).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// serveJWKS serves the JWKS on listener until ctx is done.
+func serveJWKS(ctx context.Context, listener net.Listener, log io.Writer, jwks *jose.JSONWebKeySet) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+keysPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jwks)
+	})
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	fmt.Fprintf(log, "serving JWKS on http://%s%s\n", listener.Addr(), keysPath)
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+func Test_mint(t *testing.T) {
+	dir := t.TempDir()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(privateKey)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+	templatePath := filepath.Join(dir, "template.json")
+	require.NoError(t, os.WriteFile(templatePath, []byte(`{"name": "Test User", "preferred_username": {{json .Email}}, "exp": 1}`), 0600))
+	invalidTemplatePath := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalidTemplatePath, []byte(`{"sub": {{.Unknown}}}`), 0600))
+	claimsPath := filepath.Join(dir, "claims.json")
+	require.NoError(t, os.WriteFile(claimsPath, []byte(`{"name": "File User", "locale": "en"}`), 0600))
+
+	tests := []struct {
+		name         string
+		subject      string
+		lifetime     time.Duration
+		skew         time.Duration
+		alg          string
+		format       string
+		template     string
+		claimsFile   string
+		claims       []string
+		wantClaims   map[string]any
+		wantLifetime time.Duration
+		wantExpired  bool
+		wantErr      bool
+	}{
+		{
+			name:     "no subject",
+			lifetime: time.Hour,
+			wantErr:  true,
+		},
+		{
+			name:    "zero lifetime",
+			subject: "user",
+			wantErr: true,
+		},
+		{
+			name:     "algorithm of other key type",
+			subject:  "user",
+			lifetime: time.Hour,
+			alg:      "RS256",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown format",
+			subject:  "user",
+			lifetime: time.Hour,
+			format:   "xml",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown template field",
+			subject:  "user",
+			lifetime: time.Hour,
+			template: invalidTemplatePath,
+			wantErr:  true,
+		},
+		{
+			name:       "template and claims",
+			subject:    "user",
+			lifetime:   time.Hour,
+			template:   templatePath,
+			claimsFile: claimsPath,
+			claims:     []string{"name=Other User", "groups:=[\"admins\"]"},
+			wantClaims: map[string]any{
+				"iss":                "https://idp.example.com",
+				"sub":                "user",
+				"aud":                []any{"zitadel"},
+				"email":              "user@example.com",
+				"email_verified":     true,
+				"preferred_username": "user@example.com",
+				"name":               "Other User",
+				"locale":             "en",
+				"groups":             []any{"admins"},
+			},
+			wantLifetime: time.Hour,
+		},
+		{
+			name:         "skew",
+			subject:      "user",
+			lifetime:     time.Hour,
+			skew:         time.Minute,
+			wantLifetime: time.Hour + time.Minute,
+		},
+		{
+			name:         "expired",
+			subject:      "user",
+			lifetime:     -time.Hour,
+			wantLifetime: time.Hour,
+			wantExpired:  true,
+		},
+		{
+			name:         "json format",
+			subject:      "user",
+			lifetime:     time.Hour,
+			format:       output.FormatJSON,
+			wantLifetime: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mintFlags = assertion.Flags{
+				KeyPath:    keyPath,
+				Algorithm:  tt.alg,
+				Audience:   []string{"zitadel"},
+				Lifetime:   tt.lifetime,
+				Skew:       tt.skew,
+				Claims:     tt.claims,
+				ClaimsFile: tt.claimsFile,
+			}
+			mintOutputFlags.Format = tt.format
+			mintIssuer, mintSubject, mintEmail, mintEmailVerified = "https://idp.example.com", tt.subject, "user@example.com", true
+			mintTemplate, mintOutputPath, mintJWKSPath, mintServe = tt.template, "", filepath.Join(dir, "jwks.json"), ""
+
+			var out bytes.Buffer
+			err := mint(context.Background(), &out, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			jwksData, err := os.ReadFile(mintJWKSPath)
+			require.NoError(t, err)
+			jwks := new(jose.JSONWebKeySet)
+			require.NoError(t, json.Unmarshal(jwksData, jwks))
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, "ES256", jwks.Keys[0].Algorithm)
+
+			token := strings.TrimSpace(out.String())
+			if tt.format == output.FormatJSON {
+				var doc struct {
+					Token string `json:"token"`
+					KeyID string `json:"kid"`
+				}
+				require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+				assert.Equal(t, jwks.Keys[0].KeyID, doc.KeyID)
+				token = doc.Token
+			}
+			sig, err := jose.ParseSigned(token, key.SignatureAlgorithms)
+			require.NoError(t, err)
+			assert.Equal(t, jwks.Keys[0].KeyID, sig.Signatures[0].Header.KeyID)
+			payload, err := sig.Verify(&jwks.Keys[0])
+			require.NoError(t, err)
+			claims := make(map[string]any)
+			require.NoError(t, json.Unmarshal(payload, &claims))
+			for name, want := range tt.wantClaims {
+				assert.Equal(t, want, claims[name], name)
+			}
+			iat, exp := int64(claims["iat"].(float64)), int64(claims["exp"].(float64))
+			assert.Equal(t, int64(tt.wantLifetime.Seconds()), exp-iat)
+			assert.Equal(t, tt.wantExpired, exp < time.Now().Unix())
+			if tt.skew > 0 {
+				assert.Equal(t, float64(iat), claims["nbf"])
+			}
+		})
+	}
+}
+
+func Test_serveJWKS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	jwks := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- serveJWKS(ctx, listener, io.Discard, jwks)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + keysPath)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"keys":[]}`, string(body))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/internal/secret"
+)
+
+// SecretCmd represents the secret2jwt command
+var SecretCmd = &cobra.Command{
+	Use:   "secret2jwt",
+	Short: "Convert <client ID> and <client secret> to a client assertion for client_secret_jwt",
+	Long: `Build a client assertion (RFC 7523) for the client_secret_jwt authentication method,
+signed with the client secret using HS256, HS384 or HS512.
+iss and sub are set to the client ID, the assertion has a unique jti and expires after 5 minutes unless --lifetime is set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return secret2JWT(cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+var (
+	secretClaimFlags  assertion.Flags
+	secretFlags       secret.Flags
+	secretOutputFlags output.Flags
+	clientID          string
+	hmacAlgorithm     string
+	secretOutputPath  string
+)
+
+func init() {
+	secretClaimFlags.Purpose = assertion.PurposeClientAssertion
+	secretClaimFlags.RegisterClaims(SecretCmd.Flags())
+	secretFlags.Register(SecretCmd.Flags(), "secret", "client secret")
+	secretOutputFlags.Register(SecretCmd.Flags())
+	SecretCmd.Flags().StringVar(&clientID, "id", "", "Client ID as string")
+	SecretCmd.Flags().StringVar(&hmacAlgorithm, "alg", string(jose.HS256), "signature algorithm: HS256, HS384 or HS512")
+	SecretCmd.Flags().StringVar(&secretOutputPath, "output", "", "path where the generated jwt will be saved; will print to stdout if empty")
+}
+
+func secret2JWT(out, log io.Writer) error {
+	if clientID == "" || len(secretClaimFlags.Audience) == 0 {
+		return errors.New("please provide at least an audience, client ID and secret")
+	}
+	opts, _, err := secretClaimFlags.Options()
+	if err != nil {
+		return err
+	}
+	if err = secretOutputFlags.Validate(); err != nil {
+		return err
+	}
+	clientSecret, err := secretFlags.Read(os.Stdin, log)
+	if err != nil {
+		return err
+	}
+	jwt, err := assertion.FromSecret(clientID, clientSecret, jose.SignatureAlgorithm(hmacAlgorithm), opts)
+	if err != nil {
+		return err
+	}
+	data, err := secretOutputFlags.Render(output.NewToken(jwt, 0))
+	if err != nil {
+		return err
+	}
+	if secretOutputPath != "" {
+		return output.WriteFile(secretOutputPath, data, 0600)
+	}
+	_, err = out.Write(data)
+	return err
+}
@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+)
+
+func Test_secret2JWT(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef0123456789abcdef"
+	t.Setenv("TEST_CLIENT_SECRET", secret)
+
+	tests := []struct {
+		name       string
+		clientID   string
+		audience   []string
+		alg        string
+		format     string
+		outputPath string
+		wantAlg    string
+		wantErr    bool
+	}{
+		{
+			name:     "missing audience",
+			clientID: "client",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported algorithm",
+			clientID: "client",
+			audience: []string{"https://example.com"},
+			alg:      "RS256",
+			wantErr:  true,
+		},
+		{
+			name:     "HS256 raw",
+			clientID: "client",
+			audience: []string{"https://example.com"},
+			alg:      "HS256",
+			format:   "raw",
+			wantAlg:  "HS256",
+		},
+		{
+			name:       "HS384 to file",
+			clientID:   "client",
+			audience:   []string{"https://example.com"},
+			alg:        "HS384",
+			format:     "raw",
+			outputPath: filepath.Join(t.TempDir(), "jwt"),
+			wantAlg:    "HS384",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientID = tt.clientID
+			secretClaimFlags.Audience = tt.audience
+			secretClaimFlags.Lifetime = time.Hour
+			hmacAlgorithm = tt.alg
+			secretFlags.From = "env:TEST_CLIENT_SECRET"
+			secretOutputFlags.Format = tt.format
+			secretOutputPath = tt.outputPath
+
+			var out bytes.Buffer
+			err := secret2JWT(&out, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			token := out.Bytes()
+			if tt.outputPath != "" {
+				token, err = os.ReadFile(tt.outputPath)
+				require.NoError(t, err)
+			}
+
+			sig, err := jose.ParseSigned(string(bytes.TrimSpace(token)), assertion.HMACAlgorithms)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAlg, sig.Signatures[0].Header.Algorithm)
+			payload, err := sig.Verify([]byte(secret))
+			require.NoError(t, err)
+			claims := new(struct {
+				Issuer  string `json:"iss"`
+				Subject string `json:"sub"`
+				Expiry  int64  `json:"exp"`
+			})
+			require.NoError(t, json.Unmarshal(payload, claims))
+			assert.Equal(t, "client", claims.Issuer)
+			assert.Equal(t, "client", claims.Subject)
+			assert.InDelta(t, time.Now().Add(5*time.Minute).Unix(), claims.Expiry, 1, "client assertion must default to 5 minutes")
+		})
+	}
+}
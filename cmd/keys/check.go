@@ -0,0 +1,198 @@
+package keys
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check <key file or directory>...",
+	Short: "Validate <key files> and report their expiry",
+	Long: `Validate ZITADEL key files (key.json) and report the days until they expire.
+For a directory, all *.json files in it are checked.
+Each key file must have the fields of its type (serviceaccount or application),
+a parsable private key of a sufficient size or a supported curve and must not expire within the threshold.
+The command exits with a non-zero code if any key fails the check.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return checkKeys(cmd.OutOrStdout(), args, time.Now())
+	},
+}
+
+var (
+	minDays     int
+	checkFormat string
+)
+
+func init() {
+	checkCmd.Flags().IntVar(&minDays, "days", 30, "minimum number of days a key must still be valid")
+	checkCmd.Flags().StringVar(&checkFormat, "format", output.FormatTable, "output format: table or json")
+}
+
+type status string
+
+const (
+	statusOK       status = "OK"
+	statusExpiring status = "EXPIRING"
+	statusExpired  status = "EXPIRED"
+	statusInvalid  status = "INVALID"
+)
+
+type checkResult struct {
+	Path           string     `json:"path"`
+	Type           string     `json:"type,omitempty"`
+	KeyID          string     `json:"keyId,omitempty"`
+	Subject        string     `json:"subject,omitempty"`
+	Key            string     `json:"key,omitempty"`
+	ExpirationDate *time.Time `json:"expirationDate,omitempty"`
+	DaysLeft       *int       `json:"daysLeft,omitempty"`
+	Status         status     `json:"status"`
+	Problems       []string   `json:"problems,omitempty"`
+}
+
+func checkKeys(out io.Writer, args []string, now time.Time) error {
+	if err := output.CheckFormat(checkFormat, output.FormatTable, output.FormatJSON); err != nil {
+		return err
+	}
+	paths, err := keyFilePaths(args)
+	if err != nil {
+		return err
+	}
+	results := make([]*checkResult, len(paths))
+	failed := 0
+	for i, path := range paths {
+		results[i] = checkKey(path, now)
+		if results[i].Status != statusOK {
+			failed++
+		}
+	}
+
+	if checkFormat == output.FormatJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	} else {
+		printResults(out, results)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keys failed the check", failed, len(results))
+	}
+	return nil
+}
+
+// keyFilePaths returns the paths of the files and of the *.json files in the directories.
+func keyFilePaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			// missing files are reported by checkKey, references like env:VAR are read by key.Read
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no *.json files found in %s", arg)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+func checkKey(path string, now time.Time) *checkResult {
+	result := &checkResult{Path: path, Status: statusInvalid}
+	data, err := key.Read(path, os.Stdin)
+	if err != nil {
+		result.Problems = []string{err.Error()}
+		return result
+	}
+	if format, err := key.DetectFormat(data); err != nil || format != key.FormatKeyFile {
+		result.Problems = []string{"not a ZITADEL key file"}
+		return result
+	}
+	file, err := key.ParseFile(data)
+	if err != nil {
+		result.Problems = []string{err.Error()}
+		return result
+	}
+	result.Type = file.Type
+	result.KeyID = file.KeyID
+	result.Subject = file.Subject()
+	result.Problems = problems(file.Validate())
+
+	if file.Key != "" {
+		privateKey, err := key.ParsePrivateKey([]byte(file.Key))
+		if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("private key: %v", err))
+		} else {
+			result.Key, err = key.Describe(privateKey.Public())
+			result.Problems = append(result.Problems, problems(err)...)
+		}
+	}
+	if len(result.Problems) > 0 {
+		return result
+	}
+
+	result.Status = statusOK
+	if !file.ExpirationDate.IsZero() {
+		days := int(math.Floor(file.ExpirationDate.Sub(now).Hours() / 24))
+		result.ExpirationDate = &file.ExpirationDate
+		result.DaysLeft = &days
+		switch {
+		case !now.Before(file.ExpirationDate):
+			result.Status = statusExpired
+			result.Problems = []string{"key expired"}
+		case days < minDays:
+			result.Status = statusExpiring
+			result.Problems = []string{fmt.Sprintf("key expires in less than %d days", minDays)}
+		}
+	}
+	return result
+}
+
+// problems splits the errors joined by errors.Join.
+func problems(err error) []string {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var messages []string
+		for _, err := range joined.Unwrap() {
+			messages = append(messages, err.Error())
+		}
+		return messages
+	}
+	return []string{err.Error()}
+}
+
+func printResults(out io.Writer, results []*checkResult) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tTYPE\tSUBJECT\tKEY ID\tKEY\tEXPIRES\tDAYS\tSTATUS\tPROBLEMS")
+	for _, r := range results {
+		expires, days := "-", "-"
+		if r.ExpirationDate != nil {
+			expires = r.ExpirationDate.UTC().Format(time.DateOnly)
+			days = fmt.Sprint(*r.DaysLeft)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Path, output.OrDash(r.Type), output.OrDash(r.Subject), output.OrDash(r.KeyID), output.OrDash(r.Key), expires, days, r.Status, strings.Join(r.Problems, "; "))
+	}
+	w.Flush()
+}
@@ -0,0 +1,136 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+func Test_checkKeys(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rsaPEM := keytest.PEM(t)
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	weakPEM, err := key.EncodePrivateKey(weakKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeKeyFile := func(name string, file key.File) string {
+		return keytest.Write(t, dir, name, keytest.File(t, file))
+	}
+	valid := writeKeyFile("valid.json", key.File{Type: key.TypeServiceAccount, KeyID: "1", Key: rsaPEM, UserID: "user", ExpirationDate: now.AddDate(1, 0, 0)})
+	expiring := writeKeyFile("expiring.json", key.File{Type: key.TypeServiceAccount, KeyID: "2", Key: rsaPEM, UserID: "user", ExpirationDate: now.AddDate(0, 0, 10)})
+	expired := writeKeyFile("expired.json", key.File{Type: key.TypeApplication, KeyID: "3", Key: rsaPEM, ClientID: "client", AppID: "app", ExpirationDate: now.AddDate(0, 0, -1)})
+	malformed := writeKeyFile("malformed.json", key.File{Type: key.TypeApplication, KeyID: "4", Key: rsaPEM, ClientID: "client"})
+	weak := writeKeyFile("weak.json", key.File{Type: key.TypeServiceAccount, KeyID: "5", Key: string(weakPEM), UserID: "user"})
+	pemPath := keytest.Write(t, t.TempDir(), "key.pem", []byte(rsaPEM))
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantStatus   map[string]status
+		wantProblems map[string][]string
+		wantErr      bool
+	}{
+		{
+			name:       "valid",
+			args:       []string{valid},
+			wantStatus: map[string]status{valid: statusOK},
+		},
+		{
+			name:       "expiring",
+			args:       []string{expiring},
+			wantStatus: map[string]status{expiring: statusExpiring},
+			wantErr:    true,
+		},
+		{
+			name:       "expired",
+			args:       []string{expired},
+			wantStatus: map[string]status{expired: statusExpired},
+			wantErr:    true,
+		},
+		{
+			name:         "malformed",
+			args:         []string{malformed},
+			wantStatus:   map[string]status{malformed: statusInvalid},
+			wantProblems: map[string][]string{malformed: {"appId is missing"}},
+			wantErr:      true,
+		},
+		{
+			name:         "weak key",
+			args:         []string{weak},
+			wantStatus:   map[string]status{weak: statusInvalid},
+			wantProblems: map[string][]string{weak: {"RSA key size 1024 is too small, must be at least 2048"}},
+			wantErr:      true,
+		},
+		{
+			name:         "not a key file",
+			args:         []string{pemPath},
+			wantStatus:   map[string]status{pemPath: statusInvalid},
+			wantProblems: map[string][]string{pemPath: {"not a ZITADEL key file"}},
+			wantErr:      true,
+		},
+		{
+			name: "directory",
+			args: []string{dir},
+			wantStatus: map[string]status{
+				valid:     statusOK,
+				expiring:  statusExpiring,
+				expired:   statusExpired,
+				malformed: statusInvalid,
+				weak:      statusInvalid,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "empty directory",
+			args:    []string{t.TempDir()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minDays, checkFormat = 30, output.FormatJSON
+			var out bytes.Buffer
+			err := checkKeys(&out, tt.args, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.wantStatus == nil {
+				return
+			}
+			var results []*checkResult
+			require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+			got := make(map[string]status, len(results))
+			for _, r := range results {
+				got[r.Path] = r.Status
+				if want, ok := tt.wantProblems[r.Path]; ok {
+					assert.Equal(t, want, r.Problems)
+				}
+			}
+			assert.Equal(t, tt.wantStatus, got)
+		})
+	}
+
+	t.Run("table", func(t *testing.T) {
+		minDays, checkFormat = 30, output.FormatTable
+		var out bytes.Buffer
+		require.NoError(t, checkKeys(&out, []string{valid}, now))
+		assert.Contains(t, out.String(), "STATUS")
+		assert.Contains(t, out.String(), "RSA 2048")
+		assert.Contains(t, out.String(), "2031-01-01")
+		assert.Contains(t, out.String(), "365")
+	})
+}
@@ -0,0 +1,126 @@
+package keys
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a key pair and a key.json to upload the public key to ZITADEL",
+	Long: `Generate an RSA or ECDSA key pair locally.
+The public key is written as PEM, ready to be uploaded to ZITADEL.
+The private key is written as key.json, which can be used by key2jwt once the key ID returned by ZITADEL is filled in.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generate(cmd.ErrOrStderr())
+	},
+}
+
+var (
+	algorithm     string
+	bits          int
+	curve         string
+	keyType       string
+	keyID         string
+	userID        string
+	clientID      string
+	appID         string
+	publicKeyPath string
+	keyFilePath   string
+	force         bool
+)
+
+func init() {
+	generateCmd.Flags().StringVar(&algorithm, "algorithm", key.AlgorithmRSA, "key algorithm: rsa or ec")
+	generateCmd.Flags().IntVar(&bits, "bits", 2048, "size of RSA keys: 2048, 3072 or 4096")
+	generateCmd.Flags().StringVar(&curve, "curve", "P-256", "curve of EC keys: P-256, P-384 or P-521")
+	generateCmd.Flags().StringVar(&keyType, "type", key.TypeServiceAccount, "type of the key.json: serviceaccount or application")
+	generateCmd.Flags().StringVar(&keyID, "key-id", "", "ID of the key in ZITADEL, if already known")
+	generateCmd.Flags().StringVar(&userID, "user-id", "", "ID of the service user (type serviceaccount)")
+	generateCmd.Flags().StringVar(&clientID, "client-id", "", "client ID of the application (type application)")
+	generateCmd.Flags().StringVar(&appID, "app-id", "", "ID of the application (type application)")
+	generateCmd.Flags().StringVar(&publicKeyPath, "public-key", "public.pem", "path where the public key PEM will be saved")
+	generateCmd.Flags().StringVar(&keyFilePath, "output", "key.json", "path where the key.json will be saved")
+	generateCmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
+}
+
+func generate(log io.Writer) error {
+	keyFile := &key.File{
+		Type:  keyType,
+		KeyID: keyID,
+	}
+	switch keyType {
+	case key.TypeServiceAccount:
+		keyFile.UserID = userID
+	case key.TypeApplication:
+		keyFile.ClientID = clientID
+		keyFile.AppID = appID
+	default:
+		return fmt.Errorf("unsupported key type %q, must be %s or %s", keyType, key.TypeServiceAccount, key.TypeApplication)
+	}
+	// both paths are checked before writing, so an existing public key doesn't leave a key.json without it
+	if filepath.Clean(keyFilePath) == filepath.Clean(publicKeyPath) {
+		return fmt.Errorf("--output and --public-key must be different files, both are %s", keyFilePath)
+	}
+	for _, path := range []string{keyFilePath, publicKeyPath} {
+		if err := checkFree(path); err != nil {
+			return err
+		}
+	}
+
+	privateKey, err := key.Generate(algorithm, bits, curve)
+	if err != nil {
+		return err
+	}
+	privatePEM, err := key.EncodePrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	publicPEM, err := key.EncodePublicKey(privateKey.Public())
+	if err != nil {
+		return err
+	}
+	keyFile.Key = string(privatePEM)
+	data, err := json.MarshalIndent(keyFile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = output.WriteFile(keyFilePath, data, 0600); err != nil {
+		return err
+	}
+	if err = output.WriteFile(publicKeyPath, publicPEM, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(log, "private key saved to %s, public key saved to %s\n", keyFilePath, publicKeyPath)
+	if keyFile.Validate() != nil {
+		fmt.Fprintf(log, "upload %s to ZITADEL and fill in the missing keyId, userId, clientId or appId in %s\n", publicKeyPath, keyFilePath)
+	}
+	return nil
+}
+
+// checkFree returns an error if the file name exists, unless --force is set.
+func checkFree(name string) error {
+	if force {
+		return nil
+	}
+	_, err := os.Lstat(name)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%s already exists, use --force to overwrite it", name)
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	default:
+		return err
+	}
+}
@@ -0,0 +1,119 @@
+package keys
+
+import (
+	"crypto"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+func Test_generate(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		bits      int
+		curve     string
+		keyType   string
+		existing  string
+		force     bool
+		wantErr   bool
+	}{
+		{
+			name:      "unsupported type",
+			algorithm: key.AlgorithmRSA,
+			bits:      2048,
+			keyType:   "foo",
+			wantErr:   true,
+		},
+		{
+			name:      "unsupported bits",
+			algorithm: key.AlgorithmRSA,
+			bits:      1024,
+			keyType:   key.TypeServiceAccount,
+			wantErr:   true,
+		},
+		{
+			name:      "existing key file",
+			algorithm: key.AlgorithmRSA,
+			bits:      2048,
+			keyType:   key.TypeServiceAccount,
+			existing:  "key.json",
+			wantErr:   true,
+		},
+		{
+			name:      "existing public key",
+			algorithm: key.AlgorithmRSA,
+			bits:      2048,
+			keyType:   key.TypeServiceAccount,
+			existing:  "public.pem",
+			wantErr:   true,
+		},
+		{
+			name:      "rsa serviceaccount",
+			algorithm: key.AlgorithmRSA,
+			bits:      2048,
+			keyType:   key.TypeServiceAccount,
+			existing:  "key.json",
+			force:     true,
+		},
+		{
+			name:      "ec application",
+			algorithm: key.AlgorithmEC,
+			curve:     "P-384",
+			keyType:   key.TypeApplication,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			algorithm, bits, curve, keyType, force = tt.algorithm, tt.bits, tt.curve, tt.keyType, tt.force
+			keyID, userID, clientID, appID = "kid", "user", "client", "app"
+			keyFilePath = filepath.Join(dir, "key.json")
+			publicKeyPath = filepath.Join(dir, "public.pem")
+			if tt.existing != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, tt.existing), nil, 0600))
+			}
+
+			err := generate(io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				// no file is written if one of them exists
+				entries, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				assert.LessOrEqual(t, len(entries), 1)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(keyFilePath)
+			require.NoError(t, err)
+			keyFile, err := key.ParseFile(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.keyType, keyFile.Type)
+			assert.Equal(t, "kid", keyFile.KeyID)
+
+			publicPEM, err := os.ReadFile(publicKeyPath)
+			require.NoError(t, err)
+			publicKeys, err := key.PublicKeys(publicPEM)
+			require.NoError(t, err)
+			fileKeys, err := key.PublicKeys(data)
+			require.NoError(t, err)
+			assert.True(t, fileKeys[0].Key.(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKeys[0].Key))
+
+			_, err = assertion.FromKey(data, "", "", &assertion.Options{Audience: []string{"https://example.com"}, Lifetime: time.Hour})
+			assert.NoError(t, err)
+
+			checkFormat, minDays = output.FormatTable, 30
+			assert.NoError(t, checkKeys(io.Discard, []string{keyFilePath}, time.Now()))
+		})
+	}
+}
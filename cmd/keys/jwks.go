@@ -0,0 +1,73 @@
+package keys
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/ref"
+)
+
+var jwksCmd = &cobra.Command{
+	Use:   "jwks <key>...",
+	Short: "Export <key>s (key.json, PEM or JWK) as JWKS",
+	Long: `Export one or more key.json, PEM or JWK keys as JSON Web Key Set,
+e.g. to publish the public keys used by key2jwt from a mock service.
+Each key is a path, - for stdin (only once), env:VAR or fd:N.
+The kid is taken from the keyId of a key.json or is the SHA-256 thumbprint of a PEM key.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportJWKS(cmd.OutOrStdout(), args)
+	},
+}
+
+var (
+	includePrivate bool
+	singleJWK      bool
+)
+
+func init() {
+	jwksCmd.Flags().BoolVar(&includePrivate, "private", false, "include the private key parameters, only use this for test fixtures")
+	jwksCmd.Flags().BoolVar(&singleJWK, "jwk", false, "print a single JWK instead of a JWKS; requires exactly one key file")
+}
+
+func exportJWKS(out io.Writer, paths []string) error {
+	if singleJWK && len(paths) != 1 {
+		return errors.New("--jwk requires exactly one key file")
+	}
+	refs := make([]ref.Flag, len(paths))
+	for i, path := range paths {
+		refs[i] = ref.Flag{Name: "the keys", Ref: path}
+	}
+	if err := ref.CheckStdin(refs...); err != nil {
+		return err
+	}
+	set := jose.JSONWebKeySet{Keys: make([]jose.JSONWebKey, len(paths))}
+	for i, path := range paths {
+		data, err := key.Read(path, os.Stdin)
+		if err != nil {
+			return err
+		}
+		set.Keys[i], err = key.JWK(data, includePrivate)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var doc any = set
+	if singleJWK {
+		doc = set.Keys[0]
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,83 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+func Test_exportJWKS(t *testing.T) {
+	dir := t.TempDir()
+	algorithm, bits, keyType, force = key.AlgorithmRSA, 2048, key.TypeServiceAccount, false
+	keyID, userID = "kid", "user"
+	keyFilePath = filepath.Join(dir, "key.json")
+	publicKeyPath = filepath.Join(dir, "public.pem")
+	require.NoError(t, generate(io.Discard))
+
+	tests := []struct {
+		name     string
+		paths    []string
+		jwk      bool
+		wantKeys int
+		wantErr  bool
+	}{
+		{
+			name:    "missing file",
+			paths:   []string{filepath.Join(dir, "foo.json")},
+			wantErr: true,
+		},
+		{
+			name:    "jwk with multiple files",
+			paths:   []string{keyFilePath, publicKeyPath},
+			jwk:     true,
+			wantErr: true,
+		},
+		{
+			name:     "jwks",
+			paths:    []string{keyFilePath, publicKeyPath},
+			wantKeys: 2,
+		},
+		{
+			name:     "jwk",
+			paths:    []string{keyFilePath},
+			jwk:      true,
+			wantKeys: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			singleJWK = tt.jwk
+			var out bytes.Buffer
+			err := exportJWKS(&out, tt.paths)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.jwk {
+				var got jose.JSONWebKey
+				require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+				assert.Equal(t, "kid", got.KeyID)
+				return
+			}
+			var got jose.JSONWebKeySet
+			require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+			assert.Len(t, got.Keys, tt.wantKeys)
+			assert.Equal(t, "kid", got.Keys[0].KeyID)
+		})
+	}
+	t.Run("stdin twice", func(t *testing.T) {
+		singleJWK = false
+		err := exportJWKS(io.Discard, []string{"-", keyFilePath, "-"})
+		assert.ErrorContains(t, err, "- is passed 2 times (the keys)")
+	})
+}
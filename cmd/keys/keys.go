@@ -0,0 +1,17 @@
+package keys
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the keys root command
+var Cmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Create and convert keys for ZITADEL service users and applications",
+}
+
+func init() {
+	Cmd.AddCommand(generateCmd)
+	Cmd.AddCommand(jwksCmd)
+	Cmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,179 @@
+package mockop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+)
+
+// Cmd represents the mock-op command
+var Cmd = &cobra.Command{
+	Use:   "mock-op",
+	Short: "Serve a local mock of the ZITADEL OpenID Provider for offline tests",
+	Long: `Serve discovery, the JSON Web Key Set and the authorization, device authorization, token, introspection, revocation,
+userinfo and end session endpoints of a mocked ZITADEL instance, e.g. to test services consuming the output of key2jwt in CI without network access.
+
+Tokens are issued for:
+  - JWT profile grants signed by a registered service account key (--key)
+  - client credentials grants of basic auth clients (--client) or registered application keys
+  - authorization code grants with PKCE and device authorization grants of any client,
+    which log in --user without prompting, or are denied if it is empty
+  - token exchange grants of authenticated clients, with an actor token added as act claim
+
+The access tokens and ID tokens are JWTs signed by the mock. The reserved ZITADEL scopes are validated,
+the role scopes add the configured roles and the project audience scopes the projects to the audience.
+The credentials of --client are test fixtures and visible in the process list.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		listener, err := net.Listen("tcp", listen)
+		if err != nil {
+			return err
+		}
+		return serve(ctx, listener, cmd.ErrOrStderr())
+	},
+}
+
+var (
+	listen        string
+	issuer        string
+	keyPaths      []string
+	clients       []string
+	roleKeys      []string
+	orgID         string
+	orgDomain     string
+	rolesPath     string
+	claimsPath    string
+	projectID     string
+	user          string
+	tokenLifetime time.Duration
+	signingKey    string
+)
+
+func init() {
+	Cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:9090", "address to listen on")
+	Cmd.Flags().StringVar(&issuer, "issuer", "", "issuer of the mock, if it is reached by another URL than http://<listen address>")
+	Cmd.Flags().StringArrayVar(&keyPaths, "key", nil, "path to a key.json (or - for stdin, env:VAR, fd:N) of a service account or application to accept assertions of; can be repeated")
+	Cmd.Flags().StringArrayVar(&clients, "client", nil, "client authenticating with basic auth as <client ID>:<secret>; can be repeated")
+	Cmd.Flags().StringArrayVar(&roleKeys, "role", nil, "role granted to every subject in the organization of --org-id; can be repeated")
+	Cmd.Flags().StringVar(&orgID, "org-id", "1", "ID of the organization the --role roles are granted in")
+	Cmd.Flags().StringVar(&orgDomain, "org-domain", "localhost", "primary domain of the organization the --role roles are granted in")
+	Cmd.Flags().StringVar(&rolesPath, "roles-from", "", `path to a JSON file with the roles granted to every subject, as in the roles claim: {"<role key>": {"<org ID>": "<domain>"}}`)
+	Cmd.Flags().StringVar(&claimsPath, "claims-from", "", `path to a JSON file with additional claims of the ID token and userinfo by subject: {"<user or client ID>": {"email": "..."}}`)
+	Cmd.Flags().StringVar(&projectID, "project-id", "", "project added to the audience of every token, also used for the project specific roles claim")
+	Cmd.Flags().StringVar(&user, "user", "", "subject logged in by the authorization code and device authorization grants; they are denied if empty")
+	Cmd.Flags().DurationVar(&tokenLifetime, "token-lifetime", mockop.DefaultTokenLifetime, "lifetime of the issued tokens")
+	Cmd.Flags().StringVar(&signingKey, "signing-key", "", "path to a private key (key.json, PEM or JWK) signing the tokens, so they stay valid across restarts; generated if empty")
+}
+
+// serve serves the mock OP on listener until ctx is done.
+func serve(ctx context.Context, listener net.Listener, log io.Writer) error {
+	config, err := loadConfig()
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	opIssuer := issuer
+	if opIssuer == "" {
+		opIssuer = "http://" + listener.Addr().String()
+	}
+	op, err := mockop.New(opIssuer, *config)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           op,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	fmt.Fprintf(log, "serving mock OP with issuer %s on %s\n", op.Issuer(), listener.Addr())
+	if err = srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// loadConfig reads the keys, clients, roles and claims of the flags.
+func loadConfig() (*mockop.Config, error) {
+	config := &mockop.Config{
+		Clients:       make(map[string]string, len(clients)),
+		Roles:         make(map[string]map[string]string, len(roleKeys)),
+		ProjectID:     projectID,
+		User:          user,
+		TokenLifetime: tokenLifetime,
+	}
+	for _, path := range keyPaths {
+		data, err := key.Read(path, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		config.Keys = append(config.Keys, data)
+	}
+	for _, client := range clients {
+		id, secret, ok := strings.Cut(client, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("client %q must be in the form <client ID>:<secret>", client)
+		}
+		config.Clients[id] = secret
+	}
+	if rolesPath != "" {
+		if err := readJSON(rolesPath, &config.Roles); err != nil {
+			return nil, fmt.Errorf("roles: %w", err)
+		}
+		if config.Roles == nil {
+			config.Roles = make(map[string]map[string]string, len(roleKeys))
+		}
+	}
+	for _, roleKey := range roleKeys {
+		if config.Roles[roleKey] == nil {
+			config.Roles[roleKey] = make(map[string]string, 1)
+		}
+		config.Roles[roleKey][orgID] = orgDomain
+	}
+	if claimsPath != "" {
+		if err := readJSON(claimsPath, &config.Claims); err != nil {
+			return nil, fmt.Errorf("claims: %w", err)
+		}
+	}
+	if signingKey != "" {
+		data, err := key.Read(signingKey, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := key.LoadPrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("signing key: %w", err)
+		}
+		config.SigningKey = privateKey.Signer
+	}
+	return config, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
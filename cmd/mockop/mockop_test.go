@@ -0,0 +1,98 @@
+package mockop
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+func Test_loadConfig(t *testing.T) {
+	dir := t.TempDir()
+	rolesFile := filepath.Join(dir, "roles.json")
+	require.NoError(t, os.WriteFile(rolesFile, []byte(`{"viewer":{"2":"other.example.com"}}`), 0600))
+	invalidFile := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalidFile, []byte(`["admin"]`), 0600))
+
+	tests := []struct {
+		name      string
+		clients   []string
+		roles     []string
+		rolesPath string
+		wantRoles map[string]map[string]string
+		wantErr   bool
+	}{
+		{
+			name:    "client without secret",
+			clients: []string{"client"},
+			wantErr: true,
+		},
+		{
+			name:      "invalid roles file",
+			rolesPath: invalidFile,
+			wantErr:   true,
+		},
+		{
+			name:      "roles merged",
+			clients:   []string{"client:se:cret"},
+			roles:     []string{"admin", "viewer"},
+			rolesPath: rolesFile,
+			wantRoles: map[string]map[string]string{
+				"admin":  {"1": "localhost"},
+				"viewer": {"1": "localhost", "2": "other.example.com"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients, roleKeys, rolesPath = tt.clients, tt.roles, tt.rolesPath
+			orgID, orgDomain = "1", "localhost"
+
+			config, err := loadConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"client": "se:cret"}, config.Clients)
+			assert.Equal(t, tt.wantRoles, config.Roles)
+		})
+	}
+}
+
+func Test_serve(t *testing.T) {
+	clients, roleKeys, rolesPath, projectID = []string{"client:secret"}, []string{"admin"}, "", "project"
+	orgID, orgDomain, tokenLifetime = "1", "localhost", time.Minute
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- serve(ctx, listener, io.Discard)
+	}()
+
+	client := http.DefaultClient
+	config, err := oauth.Discover(ctx, client, "http://"+listener.Addr().String())
+	require.NoError(t, err)
+	auth := oauth.ClientSecretBasic("client", "secret")
+	token, err := oauth.ClientCredentials(ctx, client, config.TokenEndpoint, auth, []string{oauth.ScopeAllProjectRoles})
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), token.ExpiresIn)
+	introspection, err := oauth.Introspect(ctx, client, config.IntrospectionEndpoint, token.AccessToken, auth)
+	require.NoError(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, oauth.Roles{"admin": {"1": "localhost"}}, introspection.Roles)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -6,15 +6,26 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/zitadel/zitadel-tools/cmd/authorize"
 	"github.com/zitadel/zitadel-tools/cmd/basicauth"
+	"github.com/zitadel/zitadel-tools/cmd/clientcredentials"
+	"github.com/zitadel/zitadel-tools/cmd/doctor"
+	"github.com/zitadel/zitadel-tools/cmd/introspect"
 	"github.com/zitadel/zitadel-tools/cmd/jwt"
+	"github.com/zitadel/zitadel-tools/cmd/keys"
 	"github.com/zitadel/zitadel-tools/cmd/migration"
+	"github.com/zitadel/zitadel-tools/cmd/mockop"
+	"github.com/zitadel/zitadel-tools/cmd/servetoken"
+	"github.com/zitadel/zitadel-tools/cmd/session"
+	"github.com/zitadel/zitadel-tools/cmd/token"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "zitadel-tools",
 	Short: "ZITADEL tools provides you with some helper tools",
+	// errors are mostly failed requests or checks, which the usage of the command would only hide
+	SilenceUsage: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -31,6 +42,21 @@ func SetVersionInfo(version, commit, date string) {
 
 func init() {
 	rootCmd.AddCommand(jwt.Cmd)
+	rootCmd.AddCommand(jwt.SecretCmd)
+	rootCmd.AddCommand(jwt.GroupCmd)
+	rootCmd.AddCommand(keys.Cmd)
 	rootCmd.AddCommand(basicauth.Cmd)
+	rootCmd.AddCommand(clientcredentials.Cmd)
 	rootCmd.AddCommand(migration.Cmd)
+	rootCmd.AddCommand(token.Cmd)
+	rootCmd.AddCommand(servetoken.Cmd)
+	rootCmd.AddCommand(authorize.URLCmd)
+	rootCmd.AddCommand(authorize.LoginCmd)
+	rootCmd.AddCommand(authorize.DeviceCmd)
+	rootCmd.AddCommand(introspect.Cmd)
+	rootCmd.AddCommand(doctor.Cmd)
+	rootCmd.AddCommand(session.UserinfoCmd)
+	rootCmd.AddCommand(session.RevokeCmd)
+	rootCmd.AddCommand(session.LogoutURLCmd)
+	rootCmd.AddCommand(mockop.Cmd)
 }
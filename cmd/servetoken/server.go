@@ -0,0 +1,260 @@
+package servetoken
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/loopback"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/refresh"
+)
+
+// SecretHeader is the header in which clients send the shared secret.
+const SecretHeader = "Zitadel-Tools-Secret"
+
+// tokenResponse is returned by the token endpoint, expires_in is the remaining lifetime.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// maxCachedTokens limits the tokens cached per key and set of scopes,
+// each of which is refreshed in the background until it is no longer requested.
+const maxCachedTokens = 100
+
+// errIdle stops the refresh of a token which was not requested within its lifetime.
+var errIdle = errors.New("token is no longer requested")
+
+// server serves cached tokens for its keys.
+type server struct {
+	// ctx ends the background refreshes.
+	ctx           context.Context
+	client        *http.Client
+	issuer        string
+	tokenEndpoint string
+	keys          map[string][]byte
+	secret        []byte
+	log           io.Writer
+	mux           *http.ServeMux
+
+	mu        sync.Mutex
+	tokens    map[string]*cachedToken
+	maxTokens int
+}
+
+// cachedToken is the latest token of a key and set of scopes.
+type cachedToken struct {
+	mu         sync.Mutex
+	token      *oauth.TokenResponse
+	expiry     time.Time
+	refreshing bool
+	// lastUsed is the time the token was last requested by a client, in Unix nanoseconds.
+	lastUsed atomic.Int64
+	// ctx ends the background refresh when it is stopped or the server shuts down.
+	ctx  context.Context
+	stop context.CancelFunc
+}
+
+func newServer(ctx context.Context, client *http.Client, issuer, tokenEndpoint string, keys map[string][]byte, secret []byte, log io.Writer) *server {
+	s := &server{
+		ctx:           ctx,
+		client:        client,
+		issuer:        issuer,
+		tokenEndpoint: tokenEndpoint,
+		keys:          keys,
+		secret:        secret,
+		log:           log,
+		mux:           http.NewServeMux(),
+		tokens:        make(map[string]*cachedToken),
+		maxTokens:     maxCachedTokens,
+	}
+	s.mux.HandleFunc("GET /token", s.handleToken)
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// reject requests for other hosts, which a browser might send after a DNS rebinding
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if !loopback.IsHost(host) {
+		writeError(w, http.StatusForbidden, "access_denied", "host is not a loopback address")
+		return
+	}
+	if len(s.secret) > 0 && subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretHeader)), s.secret) != 1 {
+		writeError(w, http.StatusUnauthorized, "invalid_client", "missing or wrong "+SecretHeader+" header")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *server) handleToken(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	subject := query.Get("key")
+	if subject == "" && len(s.keys) == 1 {
+		for k := range s.keys {
+			subject = k
+		}
+	}
+	if _, ok := s.keys[subject]; !ok {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("unknown key %q", subject))
+		return
+	}
+	var scopes []string
+	for _, scope := range query["scope"] {
+		scopes = append(scopes, strings.Fields(scope)...)
+	}
+	if len(scopes) == 0 {
+		scopes = []string{"openid", oauth.ScopeZITADELAudience}
+	}
+	scopes = oauth.WithProjectAudiences(scopes, query["project"])
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	token, expiry, err := s.token(r.Context(), subject, scopes)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "server_error", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(&tokenResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		ExpiresIn:   int64(time.Until(expiry).Seconds()),
+	})
+}
+
+// token returns the cached token of subject for the sorted scopes.
+// If there is none or it expired, a new one is requested,
+// which is then refreshed in the background until it is not requested within its lifetime.
+func (s *server) token(ctx context.Context, subject string, scopes []string) (*oauth.TokenResponse, time.Time, error) {
+	id := subject + " " + strings.Join(scopes, " ")
+	s.mu.Lock()
+	cached, ok := s.tokens[id]
+	if !ok {
+		if len(s.tokens) >= s.maxTokens {
+			s.evictLeastRecentlyUsed()
+		}
+		cached = new(cachedToken)
+		cached.ctx, cached.stop = context.WithCancel(s.ctx)
+		s.tokens[id] = cached
+	}
+	s.mu.Unlock()
+
+	cached.lastUsed.Store(time.Now().UnixNano())
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+	if cached.token != nil && time.Now().Before(cached.expiry) {
+		return cached.token, cached.expiry, nil
+	}
+	token, err := s.fetch(ctx, subject, scopes)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	cached.set(token)
+	if !cached.refreshing {
+		cached.refreshing = true
+		go s.refresh(id, cached, subject, scopes, token)
+	}
+	return cached.token, cached.expiry, nil
+}
+
+// evictLeastRecentlyUsed removes the token which was requested longest ago and stops its refresh.
+// It must be called with s.mu held.
+func (s *server) evictLeastRecentlyUsed() {
+	var oldestID string
+	var oldest int64
+	for id, cached := range s.tokens {
+		if lastUsed := cached.lastUsed.Load(); oldestID == "" || lastUsed < oldest {
+			oldestID, oldest = id, lastUsed
+		}
+	}
+	s.tokens[oldestID].stop()
+	delete(s.tokens, oldestID)
+}
+
+// remove removes the cached token with id, if it was not already replaced, and stops its refresh.
+func (s *server) remove(id string, cached *cachedToken) {
+	s.mu.Lock()
+	if s.tokens[id] == cached {
+		delete(s.tokens, id)
+	}
+	s.mu.Unlock()
+	cached.stop()
+}
+
+func (s *server) refresh(id string, cached *cachedToken, subject string, scopes []string, token *oauth.TokenResponse) {
+	loop := refresh.NewLoop(
+		func(ctx context.Context) (*oauth.TokenResponse, error) {
+			return s.refreshFetch(ctx, id, cached, subject, scopes)
+		},
+		func(token *oauth.TokenResponse) error {
+			cached.mu.Lock()
+			defer cached.mu.Unlock()
+			cached.set(token)
+			return nil
+		},
+	)
+	loop.OnError = func(err error, retryIn time.Duration) {
+		if errors.Is(err, errIdle) {
+			return
+		}
+		fmt.Fprintf(s.log, "refresh of token for %s failed, retrying in %s: %v\n", subject, retryIn.Round(time.Millisecond), err)
+	}
+	loop.RunAfter(cached.ctx, token)
+}
+
+// refreshFetch requests the next token, unless the cached one was not requested within its lifetime.
+// Then the token is removed from the cache and its refresh is stopped.
+func (s *server) refreshFetch(ctx context.Context, id string, cached *cachedToken, subject string, scopes []string) (*oauth.TokenResponse, error) {
+	cached.mu.Lock()
+	lifetime := refresh.ExpiresIn(cached.token)
+	cached.mu.Unlock()
+	idle := time.Since(time.Unix(0, cached.lastUsed.Load())) > lifetime
+	if idle {
+		s.remove(id, cached)
+		return nil, errIdle
+	}
+	return s.fetch(ctx, subject, scopes)
+}
+
+func (s *server) fetch(ctx context.Context, subject string, scopes []string) (*oauth.TokenResponse, error) {
+	jwt, err := assertion.FromKey(s.keys[subject], "", "", &assertion.Options{
+		Audience: []string{s.issuer},
+		Lifetime: assertion.DefaultLifetime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generate assertion: %w", err)
+	}
+	token, err := oauth.JWTProfile(ctx, s.client, s.tokenEndpoint, jwt, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	return token, nil
+}
+
+func (c *cachedToken) set(token *oauth.TokenResponse) {
+	c.token = token
+	c.expiry = time.Now().Add(refresh.ExpiresIn(token))
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&oauth.Error{Code: code, Description: description})
+}
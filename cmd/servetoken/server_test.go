@@ -0,0 +1,174 @@
+package servetoken
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+// countingTransport counts the requests sent to ZITADEL.
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func Test_server(t *testing.T) {
+	alice := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "alice", UserID: "alice"})
+	bob := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "bob", UserID: "bob"})
+	zitadel := mockoptest.Start(t, mockop.Config{
+		Keys:    [][]byte{alice, bob},
+		Clients: map[string]string{"api": "secret"},
+	})
+	tokenEndpoint := zitadel.Issuer() + mockop.TokenPath
+	transport := new(countingTransport)
+	client := &http.Client{Transport: transport}
+	requests := &transport.requests
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys := map[string][]byte{"alice": alice, "bob": bob}
+	srv := newServer(ctx, client, zitadel.Issuer(), tokenEndpoint, keys, []byte("secret"), io.Discard)
+
+	tests := []struct {
+		name       string
+		host       string
+		secret     string
+		query      string
+		wantStatus int
+		wantSub    string
+		wantScope  string
+	}{
+		{
+			name:       "foreign host",
+			host:       "attacker.example.com",
+			secret:     "secret",
+			query:      "key=alice",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing secret",
+			query:      "key=alice",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong secret",
+			secret:     "foo",
+			query:      "key=alice",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "ambiguous key",
+			secret:     "secret",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "default scopes",
+			secret:     "secret",
+			query:      "key=alice",
+			wantStatus: http.StatusOK,
+			wantSub:    "alice",
+			wantScope:  "openid urn:zitadel:iam:org:project:id:zitadel:aud",
+		},
+		{
+			name:       "scopes and project",
+			secret:     "secret",
+			query:      "key=bob&scope=openid+profile&project=123",
+			wantStatus: http.StatusOK,
+			wantSub:    "bob",
+			wantScope:  "openid profile urn:zitadel:iam:org:project:id:123:aud",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/token?"+tt.query, nil)
+			req.Host = "127.0.0.1:9080"
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.secret != "" {
+				req.Header.Set(SecretHeader, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantSub == "" {
+				return
+			}
+			resp := new(tokenResponse)
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+			assert.Equal(t, "Bearer", resp.TokenType)
+			assert.InDelta(t, 3600, resp.ExpiresIn, 1)
+			introspection, err := oauth.Introspect(ctx, http.DefaultClient, zitadel.Issuer()+mockop.IntrospectPath, resp.AccessToken, oauth.ClientSecretBasic("api", "secret"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSub, introspection.Subject)
+			assert.Equal(t, tt.wantScope, strings.Join(introspection.Scope, " "))
+		})
+	}
+
+	t.Run("cached per scope set", func(t *testing.T) {
+		before := requests.Load()
+		for _, query := range []string{"key=bob&project=123&scope=profile+openid", "key=bob&scope=openid&scope=profile&project=123"} {
+			req := httptest.NewRequest(http.MethodGet, "/token?"+query, nil)
+			req.Host = "localhost"
+			req.Header.Set(SecretHeader, "secret")
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+		}
+		assert.Equal(t, before, requests.Load(), "token must be served from the cache")
+	})
+	t.Run("least recently used evicted", func(t *testing.T) {
+		srv := newServer(ctx, client, zitadel.Issuer(), tokenEndpoint, keys, nil, io.Discard)
+		srv.maxTokens = 2
+		for _, scope := range []string{"a", "b", "c"} {
+			_, _, err := srv.token(context.Background(), "alice", []string{scope})
+			require.NoError(t, err)
+		}
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		assert.Len(t, srv.tokens, 2)
+		assert.NotContains(t, srv.tokens, "alice a")
+	})
+
+	t.Run("idle token no longer refreshed", func(t *testing.T) {
+		srv := newServer(ctx, client, zitadel.Issuer(), tokenEndpoint, keys, nil, io.Discard)
+		_, _, err := srv.token(context.Background(), "alice", []string{"openid"})
+		require.NoError(t, err)
+		srv.mu.Lock()
+		cached := srv.tokens["alice openid"]
+		srv.mu.Unlock()
+
+		before := requests.Load()
+		_, err = srv.refreshFetch(context.Background(), "alice openid", cached, "alice", []string{"openid"})
+		require.NoError(t, err, "recently requested token must be refreshed")
+		assert.Equal(t, before+1, requests.Load())
+
+		cached.lastUsed.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+		_, err = srv.refreshFetch(context.Background(), "alice openid", cached, "alice", []string{"openid"})
+		assert.ErrorIs(t, err, errIdle)
+		assert.Equal(t, before+1, requests.Load())
+		assert.Error(t, cached.ctx.Err(), "refresh must be stopped")
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		assert.NotContains(t, srv.tokens, "alice openid")
+	})
+}
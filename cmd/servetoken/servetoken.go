@@ -0,0 +1,137 @@
+package servetoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/loopback"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/ref"
+	"github.com/zitadel/zitadel-tools/internal/secret"
+)
+
+// Cmd represents the serve-token command
+var Cmd = &cobra.Command{
+	Use:   "serve-token",
+	Short: "Serve access tokens for <key files> on a local HTTP endpoint",
+	Long: `Load one or more key.json files and serve access tokens on a loopback HTTP endpoint,
+similar to the metadata server of a cloud provider:
+
+  GET /token?key=<user or client ID>&scope=<scope>&project=<project ID>
+
+The key parameter can be omitted if only one key is loaded, scope and project can be repeated.
+Tokens are cached per key and set of scopes and refreshed in the background before they expire,
+until they are not requested within their lifetime. At most ` + strconv.Itoa(maxCachedTokens) + ` tokens are cached.
+If a shared secret is set, clients must send it in the ` + SecretHeader + ` header.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return serveToken(cmd.Context(), cmd.ErrOrStderr())
+	},
+}
+
+var (
+	keyPaths     []string
+	issuer       string
+	listen       string
+	sharedSecret secret.Flags
+	timeout      time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringArrayVar(&keyPaths, "key", nil, "path to a key.json (or - for stdin, env:VAR, fd:N); can be repeated")
+	Cmd.Flags().StringVar(&issuer, "issuer", "", "issuer of your ZITADEL instance, used as audience of the assertions and to discover the token endpoint (e.g. https://<your domain>)")
+	Cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:9080", "loopback address to listen on")
+	sharedSecret.Register(Cmd.Flags(), "shared-secret", "shared secret")
+	Cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+}
+
+func serveToken(ctx context.Context, log io.Writer) error {
+	if len(keyPaths) == 0 || issuer == "" {
+		return errors.New("please provide at least an issuer and key param")
+	}
+	if err := loopback.CheckAddress(listen); err != nil {
+		return err
+	}
+	refs := []ref.Flag{sharedSecret.Ref()}
+	for _, path := range keyPaths {
+		refs = append(refs, ref.Flag{Name: "--key", Ref: path})
+	}
+	if err := ref.CheckStdin(refs...); err != nil {
+		return err
+	}
+	keys, err := loadKeys(keyPaths)
+	if err != nil {
+		return err
+	}
+	var headerSecret []byte
+	if sharedSecret.Provided() {
+		value, err := sharedSecret.Read(os.Stdin, log)
+		if err != nil {
+			return err
+		}
+		headerSecret = []byte(value)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client := &http.Client{Timeout: timeout}
+	config, err := oauth.Discover(ctx, client, issuer)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           newServer(ctx, client, issuer, config.TokenEndpoint, keys, headerSecret, log),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	fmt.Fprintf(log, "serving tokens on http://%s/token\n", listener.Addr())
+	if err = srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// loadKeys reads the key files and returns them by their subject (user or client ID).
+func loadKeys(paths []string) (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := key.Read(path, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := key.LoadPrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if privateKey.File == nil {
+			return nil, fmt.Errorf("%s: must be a key.json of a service account or application", path)
+		}
+		subject := privateKey.File.Subject()
+		if _, ok := keys[subject]; ok {
+			return nil, fmt.Errorf("%s: another key of %s is already loaded", path, subject)
+		}
+		keys[subject] = data
+	}
+	return keys, nil
+}
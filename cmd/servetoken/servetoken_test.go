@@ -0,0 +1,39 @@
+package servetoken
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serveToken_stdin(t *testing.T) {
+	tests := []struct {
+		name       string
+		keyPaths   []string
+		secretFrom string
+		wantErr    string
+	}{
+		{
+			name:     "repeated key",
+			keyPaths: []string{"-", "key.json", "-"},
+			wantErr:  "- is passed 2 times (--key)",
+		},
+		{
+			name:       "key and shared secret",
+			keyPaths:   []string{"-"},
+			secretFrom: "-",
+			wantErr:    "- is passed 2 times (--shared-secret-from, --key)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPaths, issuer, listen = tt.keyPaths, "https://zitadel.example.com", "127.0.0.1:0"
+			sharedSecret.Value, sharedSecret.From = "", tt.secretFrom
+
+			err := serveToken(context.Background(), io.Discard)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+// LogoutURLCmd represents the logout-url command
+var LogoutURLCmd = &cobra.Command{
+	Use:   "logout-url",
+	Short: "Build the URL of an RP-initiated logout",
+	Long: `Discover the end session endpoint of the issuer and build the URL which logs the user out of ZITADEL.
+To redirect the user back after the logout, the post logout redirect URI must be registered for the application
+and the client is identified by the ID token hint or the client ID.
+A state is generated for the redirect unless one is given; in text format it is printed to standard error,
+so the URL can be piped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return logoutURL(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+var (
+	logoutRequest oauth.EndSessionRequest
+	logoutFormat  string
+)
+
+func init() {
+	registerFlags(LogoutURLCmd.Flags())
+	LogoutURLCmd.Flags().StringVar(&logoutRequest.ClientID, "id", "", "client ID of the application")
+	LogoutURLCmd.Flags().StringVar(&logoutRequest.IDTokenHint, "id-token-hint", "", "ID token of the session to end")
+	LogoutURLCmd.Flags().StringVar(&logoutRequest.PostLogoutRedirectURI, "post-logout-redirect-uri", "", "URI registered for the application to redirect to after the logout")
+	LogoutURLCmd.Flags().StringVar(&logoutRequest.State, "state", "", "state passed to the post logout redirect URI; generated if empty")
+	LogoutURLCmd.Flags().StringVar(&logoutRequest.LogoutHint, "logout-hint", "", "login name of the user to log out")
+	LogoutURLCmd.Flags().StringVar(&logoutRequest.UILocales, "ui-locales", "", "preferred languages of the logout page, e.g. \"de en\"")
+	LogoutURLCmd.Flags().StringVar(&logoutFormat, "format", output.FormatText, "output format: text or json")
+}
+
+type logout struct {
+	URL   string `json:"url"`
+	State string `json:"state,omitempty"`
+}
+
+func logoutURL(ctx context.Context, out, log io.Writer) error {
+	if err := output.CheckFormat(logoutFormat, output.FormatText, output.FormatJSON); err != nil {
+		return err
+	}
+	req := logoutRequest
+	generated := req.PostLogoutRedirectURI != "" && req.State == ""
+	if generated {
+		var err error
+		if req.State, err = oauth.RandomValue(); err != nil {
+			return err
+		}
+	}
+	endpoint, err := discover(ctx, &http.Client{Timeout: timeout}, "end_session_endpoint", func(config *oidc.DiscoveryConfiguration) string {
+		return config.EndSessionEndpoint
+	})
+	if err != nil {
+		return err
+	}
+	result := &logout{State: req.State}
+	if result.URL, err = oauth.EndSessionURL(endpoint, &req); err != nil {
+		return err
+	}
+	if logoutFormat == output.FormatJSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	if generated {
+		// the state has to be checked at the redirect URI, but is hard to find in the URL
+		fmt.Fprintf(log, "state: %s\n", result.State)
+	}
+	_, err = fmt.Fprintln(out, result.URL)
+	return err
+}
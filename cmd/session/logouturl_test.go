@@ -0,0 +1,109 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+func Test_logoutURL(t *testing.T) {
+	op := newTestOP(t, nil)
+
+	tests := []struct {
+		name      string
+		req       oauth.EndSessionRequest
+		wantQuery url.Values
+		wantState bool
+		wantErr   bool
+	}{
+		{
+			name:    "redirect without client",
+			req:     oauth.EndSessionRequest{PostLogoutRedirectURI: "http://localhost:8080/logout"},
+			wantErr: true,
+		},
+		{
+			name:      "hint",
+			req:       oauth.EndSessionRequest{IDTokenHint: "id-token", LogoutHint: "user@acme.example.com"},
+			wantQuery: url.Values{"id_token_hint": {"id-token"}, "logout_hint": {"user@acme.example.com"}},
+		},
+		{
+			name:      "redirect with generated state",
+			req:       oauth.EndSessionRequest{ClientID: "client", PostLogoutRedirectURI: "http://localhost:8080/logout"},
+			wantQuery: url.Values{"client_id": {"client"}, "post_logout_redirect_uri": {"http://localhost:8080/logout"}},
+			wantState: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, timeout, logoutRequest, logoutFormat = op.Issuer(), 10*time.Second, tt.req, output.FormatJSON
+
+			var out bytes.Buffer
+			err := logoutURL(context.Background(), &out, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			got := new(logout)
+			require.NoError(t, json.Unmarshal(out.Bytes(), got))
+			u, err := url.Parse(got.URL)
+			require.NoError(t, err)
+			assert.Equal(t, "/oidc/v1/end_session", u.Path)
+			query := u.Query()
+			if tt.wantState {
+				assert.NotEmpty(t, got.State)
+				assert.Equal(t, got.State, query.Get("state"))
+				query.Del("state")
+			}
+			assert.Equal(t, tt.wantQuery, query)
+		})
+	}
+}
+
+func Test_logoutURL_text(t *testing.T) {
+	op := newTestOP(t, nil)
+	issuer, timeout, logoutFormat = op.Issuer(), 10*time.Second, output.FormatText
+
+	tests := []struct {
+		name    string
+		state   string
+		wantLog bool
+	}{
+		{
+			name:    "generated state",
+			wantLog: true,
+		},
+		{
+			name:  "given state",
+			state: "state",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logoutRequest = oauth.EndSessionRequest{ClientID: "client", PostLogoutRedirectURI: "http://localhost:8080/logout", State: tt.state}
+
+			var out, log bytes.Buffer
+			require.NoError(t, logoutURL(context.Background(), &out, &log))
+			u, err := url.Parse(strings.TrimSuffix(out.String(), "\n"))
+			require.NoError(t, err)
+			state := u.Query().Get("state")
+			require.NotEmpty(t, state)
+			if tt.wantLog {
+				assert.Equal(t, "state: "+state+"\n", log.String())
+			} else {
+				assert.Empty(t, log.String())
+			}
+		})
+	}
+}
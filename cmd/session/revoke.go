@@ -0,0 +1,79 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/clientauth"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/internal/ref"
+)
+
+// RevokeCmd represents the revoke command
+var RevokeCmd = &cobra.Command{
+	Use:   "revoke [<token> | -]",
+	Short: "Revoke an access or refresh <token>",
+	Long: `Discover the revocation endpoint of the issuer and revoke the access or refresh token.
+The client the token was issued to is authenticated with its client ID and secret or an application key;
+public clients, such as native or user agent applications, only need their client ID.
+The token is read from the first argument or from standard input if it is omitted or "-".
+ZITADEL confirms the revocation of unknown or already invalid tokens as well.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return revoke(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr(), args)
+	},
+}
+
+var (
+	revokeClient  clientauth.Flags
+	tokenTypeHint string
+)
+
+func init() {
+	registerFlags(RevokeCmd.Flags())
+	revokeClient.Register(RevokeCmd.Flags(), "key", "of the application the token was issued to")
+	RevokeCmd.Flags().StringVar(&tokenTypeHint, "token-type-hint", "", "type of the token: access_token or refresh_token")
+}
+
+func revoke(ctx context.Context, in io.Reader, out, log io.Writer, args []string) error {
+	switch tokenTypeHint {
+	case "", "access_token", "refresh_token":
+	default:
+		return fmt.Errorf("unsupported token type hint %q, must be access_token or refresh_token", tokenTypeHint)
+	}
+	if revokeClient.ClientID == "" && revokeClient.KeyPath == "" {
+		return clientauth.ErrMissing
+	}
+	if err := ref.CheckStdin(append(revokeClient.Refs(), output.TokenRef(args, "token"))...); err != nil {
+		return err
+	}
+	var auth oauth.ClientAuth
+	if revokeClient.Provided() {
+		var err error
+		if auth, err = revokeClient.Auth(issuer, log); err != nil {
+			return err
+		}
+	}
+	token, err := output.ReadToken(in, args, "token")
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+	endpoint, err := discover(ctx, client, "revocation_endpoint", func(config *oidc.DiscoveryConfiguration) string {
+		return config.RevocationEndpoint
+	})
+	if err != nil {
+		return err
+	}
+	if err = oauth.Revoke(ctx, client, endpoint, revokeClient.ClientID, token, tokenTypeHint, auth); err != nil {
+		return fmt.Errorf("revocation request: %w", err)
+	}
+	_, err = fmt.Fprintln(out, "Token revoked")
+	return err
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+)
+
+func Test_revoke(t *testing.T) {
+	op := newTestOP(t, nil)
+
+	tests := []struct {
+		name     string
+		clientID string
+		secret   string
+		hint     string
+		wantErr  bool
+	}{
+		{
+			name:    "no client",
+			wantErr: true,
+		},
+		{
+			name:     "unsupported hint",
+			clientID: "public",
+			hint:     "id_token",
+			wantErr:  true,
+		},
+		{
+			name:     "wrong secret",
+			clientID: "client",
+			secret:   "wrong",
+			wantErr:  true,
+		},
+		{
+			name:     "confidential client",
+			clientID: "client",
+			secret:   "secret",
+			hint:     "refresh_token",
+		},
+		{
+			name:     "public client",
+			clientID: "public",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, timeout, tokenTypeHint = op.Issuer(), 10*time.Second, tt.hint
+			revokeClient.ClientID, revokeClient.KeyPath = tt.clientID, ""
+			revokeClient.Secret.Value, revokeClient.Secret.From = tt.secret, ""
+			token, err := op.Issue("user", tt.clientID, nil)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			err = revoke(context.Background(), nil, &out, io.Discard, []string{token.AccessToken})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Token revoked\n", out.String())
+			_, err = oauth.UserInfo(context.Background(), http.DefaultClient, op.Issuer()+mockop.UserinfoPath, token.AccessToken)
+			assert.ErrorContains(t, err, "token revoked")
+		})
+	}
+}
+
+func Test_revoke_stdin(t *testing.T) {
+	issuer, tokenTypeHint = "https://zitadel.example.com", ""
+	revokeClient.ClientID, revokeClient.KeyPath = "client", ""
+	revokeClient.Secret.Value, revokeClient.Secret.From = "", "-"
+
+	err := revoke(context.Background(), strings.NewReader("token"), io.Discard, io.Discard, nil)
+	assert.ErrorContains(t, err, "- is passed 2 times (--secret-from, the token)")
+}
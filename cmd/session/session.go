@@ -0,0 +1,40 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+// flags shared by the commands working with the session of a user
+var (
+	issuer  string
+	timeout time.Duration
+)
+
+func registerFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&issuer, "issuer", "", "issuer of your ZITADEL instance, used to discover the endpoints (e.g. https://<your domain>)")
+	flags.DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+}
+
+// discover returns the endpoint of the issuer selected by endpoint, named name in errors.
+func discover(ctx context.Context, client *http.Client, name string, endpoint func(*oidc.DiscoveryConfiguration) string) (string, error) {
+	if issuer == "" {
+		return "", errors.New("please provide an issuer")
+	}
+	config, err := oauth.Discover(ctx, client, issuer)
+	if err != nil {
+		return "", err
+	}
+	if endpoint(config) == "" {
+		return "", fmt.Errorf("discovery: %s is missing", name)
+	}
+	return endpoint(config), nil
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+)
+
+// UserinfoCmd represents the userinfo command
+var UserinfoCmd = &cobra.Command{
+	Use:   "userinfo [<access token> | -]",
+	Short: "Request the claims of the user of an <access token> from the userinfo endpoint",
+	Long: `Discover the userinfo endpoint of the issuer and request the claims of the user with the access token.
+The access token is read from the first argument or from standard input if it is omitted or "-".`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return userinfo(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), args)
+	},
+}
+
+var userinfoFormat string
+
+func init() {
+	registerFlags(UserinfoCmd.Flags())
+	UserinfoCmd.Flags().StringVar(&userinfoFormat, "format", output.FormatTable, "output format: table or json")
+}
+
+func userinfo(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
+	if err := output.CheckFormat(userinfoFormat, output.FormatTable, output.FormatJSON); err != nil {
+		return err
+	}
+	token, err := output.ReadToken(in, args, "token")
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+	endpoint, err := discover(ctx, client, "userinfo_endpoint", func(config *oidc.DiscoveryConfiguration) string {
+		return config.UserinfoEndpoint
+	})
+	if err != nil {
+		return err
+	}
+	claims, err := oauth.UserInfo(ctx, client, endpoint, token)
+	if err != nil {
+		return fmt.Errorf("userinfo request: %w", err)
+	}
+	if userinfoFormat == output.FormatJSON {
+		data, err := json.MarshalIndent(claims, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	printClaims(out, claims)
+	return nil
+}
+
+// printClaims prints the claims sorted by name, strings without quotes and other values as compact JSON.
+func printClaims(out io.Writer, claims map[string]json.RawMessage) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "CLAIM\tVALUE")
+	for _, name := range slices.Sorted(maps.Keys(claims)) {
+		value := string(claims[name])
+		var s string
+		var compact bytes.Buffer
+		if err := json.Unmarshal(claims[name], &s); err == nil {
+			value = s
+		} else if err = json.Compact(&compact, claims[name]); err == nil {
+			value = compact.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, value)
+	}
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+// newTestOP returns a mock OP with the confidential client "client" with the secret "secret" and the user "user",
+// who has an email and is granted the role admin. The clock of the OP is now, or the current time if nil.
+func newTestOP(t *testing.T, now func() time.Time) *mockop.OP {
+	return mockoptest.Start(t, mockop.Config{
+		Clients: map[string]string{"client": "secret"},
+		Roles:   map[string]map[string]string{"admin": {"1": "acme.example.com"}},
+		Claims:  map[string]map[string]any{"user": {"email": "user@acme.example.com", "email_verified": true, "updated_at": 1760000000}},
+		Now:     now,
+	})
+}
+
+func Test_userinfo(t *testing.T) {
+	// the expired token is issued with the clock set back
+	var offset time.Duration
+	op := newTestOP(t, func() time.Time { return time.Now().Add(offset) })
+	scopes := []string{"openid", oauth.ScopeAllProjectRoles}
+	active, err := op.Issue("user", "client", scopes)
+	require.NoError(t, err)
+	offset = -2 * mockop.DefaultTokenLifetime
+	expired, err := op.Issue("user", "client", scopes)
+	require.NoError(t, err)
+	offset = 0
+
+	tests := []struct {
+		name      string
+		token     string
+		format    string
+		wantLines []string
+		wantErr   bool
+	}{
+		{
+			name:    "expired",
+			token:   expired.AccessToken,
+			format:  output.FormatTable,
+			wantErr: true,
+		},
+		{
+			name:   "table",
+			token:  active.AccessToken,
+			format: output.FormatTable,
+			wantLines: []string{
+				"email                              user@acme.example.com",
+				"email_verified                     true",
+				"updated_at                         1760000000",
+				`urn:zitadel:iam:org:project:roles  {"admin":{"1":"acme.example.com"}}`,
+			},
+		},
+		{
+			name:      "json",
+			token:     active.AccessToken,
+			format:    output.FormatJSON,
+			wantLines: []string{`  "updated_at": 1760000000,`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, timeout, userinfoFormat = op.Issuer(), 10*time.Second, tt.format
+
+			var out bytes.Buffer
+			err := userinfo(context.Background(), strings.NewReader(tt.token), &out, nil)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "token expired")
+				return
+			}
+			require.NoError(t, err)
+			lines := strings.Split(out.String(), "\n")
+			for _, line := range tt.wantLines {
+				assert.Contains(t, lines, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/clientauth"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/internal/ref"
+	"github.com/zitadel/zitadel-tools/internal/secret"
+)
+
+var exchangeCmd = &cobra.Command{
+	Use:   "exchange",
+	Short: "Exchange a <subject token> for a new token with the token exchange grant, e.g. for impersonation",
+	Long: `Exchange a subject token for a new token with the token exchange grant (RFC 8693).
+With an actor token the new token is issued for the subject on behalf of the actor (impersonation),
+which requires impersonation to be allowed for the instance and organization and the actor to have the impersonator role.
+The actor token can be signed with a key.json of a service user instead, which is sent as token type jwt.
+The client doing the exchange is authenticated with its client ID and secret or an application key,
+and the token exchange grant type must be enabled for it.
+If the new token is a JWT, its act claim is printed to standard error.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exchange(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+// actorTokenLifetime is the lifetime of an actor token signed with --actor-key, which is only used once.
+const actorTokenLifetime = 5 * time.Minute
+
+var (
+	exchangeIssuer     string
+	subjectToken       secret.Flags
+	subjectTokenType   string
+	actorToken         secret.Flags
+	actorTokenType     string
+	actorKeyPath       string
+	requestedTokenType string
+	exchangeAudience   []string
+	exchangeScopes     []string
+	exchangeProjects   []string
+	exchangeClient     clientauth.Flags
+	exchangeOutput     output.Flags
+	exchangeFull       bool
+	exchangeTimeout    time.Duration
+)
+
+func init() {
+	flags := exchangeCmd.Flags()
+	flags.StringVar(&exchangeIssuer, "issuer", "", "issuer of your ZITADEL instance, used to discover the token endpoint (e.g. https://<your domain>)")
+	subjectToken.Register(flags, "subject-token", "subject token")
+	flags.StringVar(&subjectTokenType, "subject-token-type", "access_token", "type of the subject token: access_token, id_token, jwt or refresh_token")
+	actorToken.Register(flags, "actor-token", "actor token")
+	flags.StringVar(&actorTokenType, "actor-token-type", "access_token", "type of the actor token: access_token, id_token or jwt")
+	flags.StringVar(&actorKeyPath, "actor-key", "", "key.json of a service user to sign the actor token with (token type jwt); a path, - for stdin, env:VAR or fd:N")
+	flags.StringVar(&requestedTokenType, "requested-token-type", "access_token", "type of the requested token: access_token, jwt or id_token")
+	flags.StringSliceVar(&exchangeAudience, "audience", nil, "audience of the requested token; can be repeated")
+	flags.StringSliceVar(&exchangeScopes, "scope", nil, "scopes of the requested token, the scopes of the subject token if empty; can be repeated")
+	flags.StringSliceVar(&exchangeProjects, "project", nil, "ID of a project to add to the audience of the token (urn:zitadel:iam:org:project:id:{id}:aud); can be repeated")
+	exchangeClient.Register(flags, "client-key", "of the application doing the exchange")
+	exchangeOutput.Register(flags)
+	flags.BoolVar(&exchangeFull, "full", false, "print the full token response as JSON instead of the token only")
+	flags.DurationVar(&exchangeTimeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+	Cmd.AddCommand(exchangeCmd)
+}
+
+func exchange(ctx context.Context, out, log io.Writer) error {
+	if exchangeIssuer == "" {
+		return errors.New("please provide an issuer")
+	}
+	if err := exchangeOutput.Validate(); err != nil {
+		return err
+	}
+	if exchangeFull && exchangeOutput.Format != output.FormatRaw {
+		return errors.New("--full can't be combined with --format")
+	}
+	refs := append(exchangeClient.Refs(), subjectToken.Ref(), actorToken.Ref(), ref.Flag{Name: "--actor-key", Ref: actorKeyPath})
+	if err := ref.CheckStdin(refs...); err != nil {
+		return err
+	}
+	req, err := exchangeRequest(log)
+	if err != nil {
+		return err
+	}
+	auth, err := exchangeClient.Auth(exchangeIssuer, log)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: exchangeTimeout}
+	config, err := oauth.Discover(ctx, client, exchangeIssuer)
+	if err != nil {
+		return err
+	}
+	resp, err := oauth.TokenExchange(ctx, client, config.TokenEndpoint, req, auth)
+	if err != nil {
+		return fmt.Errorf("token exchange: %w", err)
+	}
+	printActor(log, resp.AccessToken)
+
+	var data []byte
+	if exchangeFull {
+		if data, err = resp.IndentedJSON(); err == nil {
+			data = append(data, '\n')
+		}
+	} else {
+		data, err = exchangeOutput.Render(output.FromResponse(resp))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// exchangeRequest reads the subject and actor tokens and builds the token exchange request.
+func exchangeRequest(log io.Writer) (*oauth.TokenExchangeRequest, error) {
+	req := &oauth.TokenExchangeRequest{
+		Audience: exchangeAudience,
+		Scopes:   oauth.WithProjectAudiences(exchangeScopes, exchangeProjects),
+	}
+	var err error
+	if req.SubjectTokenType, err = oauth.ParseTokenType(subjectTokenType); err != nil {
+		return nil, err
+	}
+	if req.RequestedTokenType, err = oauth.ParseTokenType(requestedTokenType); err != nil {
+		return nil, err
+	}
+	if req.SubjectToken, err = subjectToken.Read(os.Stdin, log); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case actorKeyPath != "" && actorToken.Provided():
+		return nil, errors.New("--actor-key can't be combined with an actor token")
+	case actorKeyPath != "":
+		data, err := key.Read(actorKeyPath, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		req.ActorToken, err = assertion.FromKey(data, "", "", &assertion.Options{
+			Audience: []string{exchangeIssuer},
+			Lifetime: actorTokenLifetime,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("actor token: %w", err)
+		}
+		req.ActorTokenType = oidc.JWTTokenType
+	case actorToken.Provided():
+		if req.ActorTokenType, err = oauth.ParseTokenType(actorTokenType); err != nil {
+			return nil, err
+		}
+		if req.ActorToken, err = actorToken.Read(os.Stdin, log); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// printActor prints the act claim of the token to log, if it is a JWT.
+func printActor(log io.Writer, token string) {
+	parts := strings.Split(token, ".")
+	switch len(parts) {
+	case 3:
+	case 5:
+		fmt.Fprintln(log, "The token is encrypted (JWE), its act claim can't be read without the decryption key")
+		return
+	default:
+		fmt.Fprintln(log, "The token is opaque, request a JWT with --requested-token-type=jwt to see its act claim")
+		return
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	claims := new(struct {
+		Actor json.RawMessage `json:"act"`
+	})
+	if err == nil {
+		err = json.Unmarshal(payload, claims)
+	}
+	if err != nil {
+		fmt.Fprintf(log, "WARN: the claims of the token can't be decoded: %v\n", err)
+		return
+	}
+	if claims.Actor == nil {
+		fmt.Fprintln(log, "The token has no act claim")
+		return
+	}
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, claims.Actor, "", "  "); err != nil {
+		buf.Reset()
+		buf.Write(claims.Actor)
+	}
+	fmt.Fprintf(log, "act: %s\n", buf.String())
+}
@@ -0,0 +1,134 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_exchange(t *testing.T) {
+	op, keyPath := newTestOP(t)
+	subject, err := op.Issue("alice", "app", []string{"openid"})
+	require.NoError(t, err)
+	actor, err := op.Issue("admin", "app", nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		actorToken         string
+		actorKey           string
+		requestedTokenType string
+		secret             string
+		wantLog            string
+		wantErr            bool
+	}{
+		{
+			name:               "wrong client secret",
+			requestedTokenType: "access_token",
+			secret:             "wrong",
+			wantErr:            true,
+		},
+		{
+			name:               "unsupported token type",
+			requestedTokenType: "saml2",
+			secret:             "secret",
+			wantErr:            true,
+		},
+		{
+			name:               "access token",
+			requestedTokenType: "access_token",
+			secret:             "secret",
+			wantLog:            "The token has no act claim",
+		},
+		{
+			name:               "actor token",
+			actorToken:         actor.AccessToken,
+			requestedTokenType: "jwt",
+			secret:             "secret",
+			wantLog:            `"sub": "admin"`,
+		},
+		{
+			name:               "actor key",
+			actorKey:           keyPath,
+			requestedTokenType: "jwt",
+			secret:             "secret",
+			wantLog:            `"sub": "user"`,
+		},
+		{
+			name:               "actor key and token",
+			actorToken:         actor.AccessToken,
+			actorKey:           keyPath,
+			requestedTokenType: "jwt",
+			secret:             "secret",
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchangeIssuer, exchangeClient.ClientID, exchangeClient.KeyPath, exchangeTimeout = op.Issuer(), "client", "", 10*time.Second
+			exchangeClient.Secret.Value, exchangeClient.Secret.From = tt.secret, ""
+			subjectToken.Value, subjectToken.From, subjectTokenType = subject.AccessToken, "", "access_token"
+			actorToken.Value, actorToken.From, actorTokenType, actorKeyPath = tt.actorToken, "", "access_token", tt.actorKey
+			requestedTokenType, exchangeAudience, exchangeScopes, exchangeProjects = tt.requestedTokenType, nil, nil, nil
+			exchangeOutput.Format, exchangeFull = "raw", false
+
+			var out, log bytes.Buffer
+			err := exchange(context.Background(), &out, &log)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, out.String())
+			assert.Contains(t, log.String(), tt.wantLog)
+		})
+	}
+}
+
+func Test_exchange_stdin(t *testing.T) {
+	exchangeIssuer, exchangeClient.ClientID, exchangeClient.KeyPath = "https://zitadel.example.com", "client", ""
+	exchangeClient.Secret.Value, exchangeClient.Secret.From = "", "-"
+	subjectToken.Value, subjectToken.From = "", "-"
+	actorToken.Value, actorToken.From, actorKeyPath = "", "", ""
+	exchangeOutput.Format, exchangeFull = "raw", false
+
+	err := exchange(context.Background(), io.Discard, io.Discard)
+	assert.ErrorContains(t, err, "- is passed 2 times (--secret-from, --subject-token-from)")
+}
+
+func Test_printActor(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantLog string
+	}{
+		{
+			name:    "opaque",
+			token:   "exchanged",
+			wantLog: "The token is opaque",
+		},
+		{
+			name:    "encrypted",
+			token:   "header.key.iv.ciphertext.tag",
+			wantLog: "The token is encrypted (JWE)",
+		},
+		{
+			name:    "without act claim",
+			token:   "e30." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`)) + ".",
+			wantLog: "The token has no act claim",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log bytes.Buffer
+			printActor(&log, tt.token)
+			assert.Contains(t, log.String(), tt.wantLog)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/output"
+	"github.com/zitadel/zitadel-tools/internal/refresh"
+)
+
+// Cmd represents the token command
+var Cmd = &cobra.Command{
+	Use:   "token",
+	Short: "Exchange a <key file> for an <access token> using the JWT profile grant",
+	Long: `Sign an assertion with the key.json or private key.pem like key2jwt does,
+discover the token endpoint of the first audience and exchange the assertion for an access token.
+
+With --watch the command keeps running, e.g. as a sidecar, and replaces the --output file
+with a new token before the current one expires, until it receives SIGINT or SIGTERM.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return token(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+// minRefreshInterval is the shortest time between two refreshes with --watch.
+var minRefreshInterval = refresh.DefaultMinInterval
+
+var (
+	keyFlags    assertion.Flags
+	outputFlags output.Flags
+	scopes      []string
+	projects    []string
+	full        bool
+	timeout     time.Duration
+	outputPath  string
+	watch       bool
+)
+
+func init() {
+	keyFlags.Register(Cmd.Flags())
+	outputFlags.Register(Cmd.Flags())
+	Cmd.Flags().StringSliceVar(&scopes, "scope", []string{"openid", oauth.ScopeZITADELAudience}, "scopes to request; can be repeated")
+	Cmd.Flags().StringSliceVar(&projects, "project", nil, "ID of a project to add to the audience of the token (urn:zitadel:iam:org:project:id:{id}:aud); can be repeated")
+	Cmd.Flags().BoolVar(&full, "full", false, "print the full token response as JSON instead of the access token only")
+	Cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the requests to ZITADEL")
+	Cmd.Flags().StringVar(&outputPath, "output", "", "path to the file the token is written to, which is replaced atomically")
+	Cmd.Flags().BoolVar(&watch, "watch", false, "keep running and refresh the token in the output file before it expires")
+}
+
+func token(ctx context.Context, out, log io.Writer) error {
+	if keyFlags.KeyPath == "" || len(keyFlags.Audience) == 0 {
+		return errors.New("please provide at least an audience and key param")
+	}
+	if watch && outputPath == "" {
+		return errors.New("please provide an output path when using watch")
+	}
+	if err := outputFlags.Validate(); err != nil {
+		return err
+	}
+	if full && outputFlags.Format != output.FormatRaw {
+		return errors.New("--full can't be combined with --format")
+	}
+	if _, _, err := keyFlags.Options(); err != nil {
+		return err
+	}
+	// the key is read once, as stdin and file descriptors can't be read again for a refresh
+	keyData, err := key.Read(keyFlags.KeyPath, os.Stdin)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+	if watch {
+		return watchToken(ctx, client, keyData, log)
+	}
+	resp, err := requestToken(ctx, client, keyData)
+	if err != nil {
+		return err
+	}
+	data, err := render(resp)
+	if err != nil {
+		return err
+	}
+	if outputPath != "" {
+		return output.WriteFile(outputPath, data, 0600)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// watchToken writes a new token to the output file before the current one expires,
+// until ctx is done or the process is interrupted or terminated.
+func watchToken(ctx context.Context, client *http.Client, keyData []byte, log io.Writer) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	loop := refresh.NewLoop(
+		func(ctx context.Context) (*oauth.TokenResponse, error) {
+			return requestToken(ctx, client, keyData)
+		},
+		func(resp *oauth.TokenResponse) error {
+			data, err := render(resp)
+			if err != nil {
+				return err
+			}
+			if err = output.WriteFile(outputPath, data, 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(log, "wrote token to %s, expires in %s\n", outputPath, refresh.ExpiresIn(resp))
+			return nil
+		},
+	)
+	loop.MinInterval = minRefreshInterval
+	loop.OnError = func(err error, retryIn time.Duration) {
+		fmt.Fprintf(log, "refresh failed, retrying in %s: %v\n", retryIn.Round(time.Millisecond), err)
+	}
+	return loop.Run(ctx)
+}
+
+// render returns the access token in the output format or, with --full, the whole token response.
+func render(resp *oauth.TokenResponse) ([]byte, error) {
+	if !full {
+		return outputFlags.Render(output.FromResponse(resp))
+	}
+	data, err := resp.IndentedJSON()
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// requestToken signs an assertion with the key and exchanges it at the token endpoint of the first audience.
+func requestToken(ctx context.Context, client *http.Client, keyData []byte) (*oauth.TokenResponse, error) {
+	jwt, err := keyFlags.Sign(keyData)
+	if err != nil {
+		return nil, fmt.Errorf("generate assertion: %w", err)
+	}
+	config, err := oauth.Discover(ctx, client, keyFlags.Audience[0])
+	if err != nil {
+		return nil, err
+	}
+	resp, err := oauth.JWTProfile(ctx, client, config.TokenEndpoint, jwt, oauth.WithProjectAudiences(scopes, projects))
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,172 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+	"github.com/zitadel/zitadel-tools/internal/refresh"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+func Test_token(t *testing.T) {
+	op, keyPath := newTestOP(t)
+	issuer := op.Issuer()
+
+	tests := []struct {
+		name     string
+		audience string
+		projects []string
+		full     bool
+		format   string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "issuer mismatch",
+			audience: issuer + "/",
+			wantErr:  true,
+		},
+		{
+			name:     "access token",
+			audience: issuer,
+			want:     `^eyJ[\w-]+\.[\w-]+\.[\w-]+\n$`,
+		},
+		{
+			name:     "header format",
+			audience: issuer,
+			format:   "header",
+			want:     `^Authorization: Bearer eyJ[\w-]+\.[\w-]+\.[\w-]+\n$`,
+		},
+		{
+			name:     "full with format",
+			audience: issuer,
+			full:     true,
+			format:   "json",
+			wantErr:  true,
+		},
+		{
+			name:     "full response",
+			audience: issuer,
+			projects: []string{"123"},
+			full:     true,
+			want:     `(?s)^\{\n.*  "expires_in": 3600,\n.*  "scope": "openid urn:zitadel:iam:org:project:id:zitadel:aud urn:zitadel:iam:org:project:id:123:aud"\n\}\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyFlags.KeyPath = keyPath
+			keyFlags.Audience = []string{tt.audience}
+			keyFlags.Lifetime = time.Hour
+			scopes = []string{"openid", "urn:zitadel:iam:org:project:id:zitadel:aud"}
+			projects = tt.projects
+			full = tt.full
+			outputFlags.Format = tt.format
+			if tt.format == "" {
+				outputFlags.Format = "raw"
+			}
+			outputPath = ""
+			watch = false
+
+			var out bytes.Buffer
+			err := token(context.Background(), &out, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Regexp(t, tt.want, out.String())
+		})
+	}
+}
+
+func Test_token_watch(t *testing.T) {
+	op, keyPath := newTestOP(t)
+	keyFlags.KeyPath = keyPath
+	keyFlags.Audience = []string{op.Issuer()}
+	keyFlags.Lifetime = time.Hour
+	scopes = []string{"openid"}
+	projects = nil
+	full = false
+	outputFlags.Format = "raw"
+	outputPath = filepath.Join(t.TempDir(), "token")
+	watch = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- token(ctx, io.Discard, io.Discard)
+	}()
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(outputPath)
+		return err == nil && strings.HasPrefix(string(data), "eyJ")
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not stop")
+	}
+
+	outputPath = ""
+	assert.Error(t, token(context.Background(), io.Discard, io.Discard))
+}
+
+func Test_token_watch_refresh(t *testing.T) {
+	data := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user"})
+	op := mockoptest.Start(t, mockop.Config{Keys: [][]byte{data}, TokenLifetime: time.Second})
+	// the key is passed as file descriptor, which can only be read once
+	fd, err := syscall.Open(keytest.Write(t, t.TempDir(), "key.json", data), syscall.O_RDONLY, 0)
+	require.NoError(t, err)
+	keyFlags.KeyPath = fmt.Sprintf("fd:%d", fd)
+	keyFlags.Audience = []string{op.Issuer()}
+	keyFlags.Lifetime = time.Hour
+	scopes, projects, full = []string{"openid"}, nil, false
+	outputFlags.Format = "raw"
+	outputPath = filepath.Join(t.TempDir(), "token")
+	watch = true
+	minRefreshInterval = 10 * time.Millisecond
+	t.Cleanup(func() { minRefreshInterval = refresh.DefaultMinInterval })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- token(ctx, io.Discard, io.Discard)
+	}()
+	var first []byte
+	require.Eventually(t, func() bool {
+		first, err = os.ReadFile(outputPath)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(outputPath)
+		return err == nil && string(data) != string(first)
+	}, 5*time.Second, 10*time.Millisecond, "the token must be refreshed with the key read before")
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+// newTestOP returns a mock OP with a service account key of the user "user" registered and the path to its key.json.
+// The client "client" with the secret "secret" can exchange tokens.
+func newTestOP(t *testing.T) (*mockop.OP, string) {
+	data := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user"})
+	op := mockoptest.Start(t, mockop.Config{
+		Keys:    [][]byte{data},
+		Clients: map[string]string{"client": "secret"},
+	})
+	return op, keytest.Write(t, t.TempDir(), "key.json", data)
+}
@@ -0,0 +1,164 @@
+// Package assertion builds and signs the JWT assertions used to authenticate against ZITADEL.
+package assertion
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	// PurposeGrant is an assertion used as authorization grant (RFC 7523, section 2.1),
+	// as it is used by service users to request a token with the JWT profile grant.
+	PurposeGrant = "grant"
+	// PurposeClientAssertion is an assertion used for client authentication (RFC 7523, section 2.2),
+	// as it is used by applications with the private_key_jwt authentication method.
+	PurposeClientAssertion = "client-assertion"
+
+	// DefaultLifetime is the lifetime of an assertion if none is set.
+	DefaultLifetime = time.Hour
+	// DefaultClientAssertionLifetime is the lifetime of a client assertion if none is set.
+	DefaultClientAssertionLifetime = 5 * time.Minute
+	// MaxClientAssertionLifetime is the longest accepted lifetime of a client assertion.
+	MaxClientAssertionLifetime = time.Hour
+)
+
+// reservedClaims are set from the [Options] and the key and can't be overwritten by custom claims.
+var reservedClaims = []string{"iss", "sub", "aud", "iat", "nbf", "exp", "jti"}
+
+// Options define the claims of a signed assertion.
+type Options struct {
+	// Audience of the assertion, which must contain the issuer of the ZITADEL instance.
+	Audience []string
+	// Lifetime of the assertion from the moment of signing.
+	Lifetime time.Duration
+	// Skew backdates the iat and nbf claims to tolerate clock drift between the signer and ZITADEL.
+	Skew time.Duration
+	// Claims are additional private claims added to the assertion.
+	Claims map[string]any
+	// Purpose of the assertion, [PurposeGrant] if empty.
+	Purpose string
+}
+
+// Validate rejects options which would result in an unusable assertion.
+func (o *Options) Validate() error {
+	if len(o.Audience) == 0 {
+		return errors.New("at least one audience is required")
+	}
+	if slices.Contains(o.Audience, "") {
+		return errors.New("audience must not be empty")
+	}
+	if o.Lifetime <= 0 {
+		return fmt.Errorf("lifetime must be positive, got %s", o.Lifetime)
+	}
+	if o.Skew < 0 {
+		return fmt.Errorf("skew must not be negative, got %s", o.Skew)
+	}
+	if o.Skew >= o.Lifetime {
+		return fmt.Errorf("skew %s must be shorter than the lifetime %s", o.Skew, o.Lifetime)
+	}
+	switch o.Purpose {
+	case "", PurposeGrant:
+	case PurposeClientAssertion:
+		if o.Lifetime > MaxClientAssertionLifetime {
+			return fmt.Errorf("lifetime of a client assertion must not exceed %s, got %s", MaxClientAssertionLifetime, o.Lifetime)
+		}
+	default:
+		return fmt.Errorf("unsupported purpose %q, must be %s or %s", o.Purpose, PurposeGrant, PurposeClientAssertion)
+	}
+	for claim := range o.Claims {
+		if slices.Contains(reservedClaims, claim) {
+			return fmt.Errorf("claim %q is reserved and can't be set as custom claim", claim)
+		}
+	}
+	return nil
+}
+
+// Sign validates the options and returns the assertion for issuer and subject signed by signer.
+func Sign(signer jose.Signer, issuer, subject string, opts *Options) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := make(map[string]any, len(opts.Claims)+len(reservedClaims))
+	maps.Copy(claims, opts.Claims)
+	claims["iss"] = issuer
+	claims["sub"] = subject
+	claims["aud"] = opts.Audience
+	claims["iat"] = now.Add(-opts.Skew).Unix()
+	claims["exp"] = now.Add(opts.Lifetime).Unix()
+	if opts.Skew > 0 {
+		claims["nbf"] = now.Add(-opts.Skew).Unix()
+	}
+	if opts.Purpose == PurposeClientAssertion {
+		// a client assertion must only be used once, which the server enforces by its jti
+		jti, err := newJWTID()
+		if err != nil {
+			return "", err
+		}
+		claims["jti"] = jti
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshal claims: %w", err)
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("sign assertion: %w", err)
+	}
+	return signed.CompactSerialize()
+}
+
+func newJWTID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generate jti: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
+
+// ParseClaims merges the claims from the JSON object in file (if not empty)
+// with the claim pairs, which take precedence.
+// A pair in the form key=value sets a string claim,
+// a pair in the form key:=json sets a claim from a raw JSON value (e.g. numbers or arrays).
+func ParseClaims(pairs []string, file string) (map[string]any, error) {
+	claims := make(map[string]any, len(pairs))
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("claims file: %w", err)
+		}
+		if err = json.Unmarshal(data, &claims); err != nil {
+			return nil, fmt.Errorf("claims file: %w", err)
+		}
+		if claims == nil {
+			claims = make(map[string]any, len(pairs))
+		}
+	}
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || name == ":" {
+			return nil, fmt.Errorf("claim %q must be in the form key=value or key:=json", pair)
+		}
+		if raw, isJSON := strings.CutSuffix(name, ":"); isJSON {
+			var v any
+			if err := json.Unmarshal([]byte(value), &v); err != nil {
+				return nil, fmt.Errorf("claim %q: %w", raw, err)
+			}
+			claims[raw] = v
+			continue
+		}
+		claims[name] = value
+	}
+	return claims, nil
+}
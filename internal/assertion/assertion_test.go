@@ -0,0 +1,220 @@
+package assertion
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{
+			name:    "no audience",
+			opts:    Options{Lifetime: time.Hour},
+			wantErr: true,
+		},
+		{
+			name:    "empty audience",
+			opts:    Options{Audience: []string{"https://example.com", ""}, Lifetime: time.Hour},
+			wantErr: true,
+		},
+		{
+			name:    "zero lifetime",
+			opts:    Options{Audience: []string{"https://example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative skew",
+			opts:    Options{Audience: []string{"https://example.com"}, Lifetime: time.Hour, Skew: -time.Minute},
+			wantErr: true,
+		},
+		{
+			name:    "skew exceeds lifetime",
+			opts:    Options{Audience: []string{"https://example.com"}, Lifetime: time.Minute, Skew: time.Minute},
+			wantErr: true,
+		},
+		{
+			name:    "reserved claim",
+			opts:    Options{Audience: []string{"https://example.com"}, Lifetime: time.Hour, Claims: map[string]any{"exp": 1}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported purpose",
+			opts:    Options{Audience: []string{"https://example.com"}, Lifetime: time.Hour, Purpose: "foo"},
+			wantErr: true,
+		},
+		{
+			name:    "long lived client assertion",
+			opts:    Options{Audience: []string{"https://example.com"}, Lifetime: 2 * time.Hour, Purpose: PurposeClientAssertion},
+			wantErr: true,
+		},
+		{
+			name:    "jti as custom claim",
+			opts:    Options{Audience: []string{"https://example.com"}, Lifetime: time.Hour, Claims: map[string]any{"jti": "1"}},
+			wantErr: true,
+		},
+		{
+			name: "valid",
+			opts: Options{Audience: []string{"https://example.com"}, Lifetime: time.Hour, Skew: time.Minute, Claims: map[string]any{"foo": "bar"}},
+		},
+		{
+			name: "valid client assertion",
+			opts: Options{Audience: []string{"https://example.com"}, Lifetime: time.Minute, Purpose: PurposeClientAssertion},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: privateKey}, nil)
+	require.NoError(t, err)
+
+	before := time.Now()
+	token, err := Sign(signer, "issuer", "subject", &Options{
+		Audience: []string{"https://a.example.com", "https://b.example.com"},
+		Lifetime: 5 * time.Minute,
+		Skew:     30 * time.Second,
+		Claims:   map[string]any{"foo": "bar"},
+	})
+	require.NoError(t, err)
+
+	sig, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+	payload, err := sig.Verify(&privateKey.PublicKey)
+	require.NoError(t, err)
+	claims := new(struct {
+		Issuer    string   `json:"iss"`
+		Subject   string   `json:"sub"`
+		Audience  []string `json:"aud"`
+		IssuedAt  int64    `json:"iat"`
+		NotBefore int64    `json:"nbf"`
+		Expiry    int64    `json:"exp"`
+		Foo       string   `json:"foo"`
+	})
+	require.NoError(t, json.Unmarshal(payload, claims))
+	assert.Equal(t, "issuer", claims.Issuer)
+	assert.Equal(t, "subject", claims.Subject)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, claims.Audience)
+	assert.Equal(t, "bar", claims.Foo)
+	assert.Equal(t, claims.IssuedAt, claims.NotBefore)
+	assert.InDelta(t, before.Add(-30*time.Second).Unix(), claims.IssuedAt, 1)
+	assert.InDelta(t, before.Add(5*time.Minute).Unix(), claims.Expiry, 1)
+
+	_, err = Sign(signer, "issuer", "subject", &Options{})
+	assert.Error(t, err)
+}
+
+func TestSign_clientAssertion(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: privateKey}, nil)
+	require.NoError(t, err)
+	opts := &Options{
+		Audience: []string{"https://example.com"},
+		Lifetime: DefaultClientAssertionLifetime,
+		Purpose:  PurposeClientAssertion,
+	}
+
+	jtis := make(map[string]bool)
+	for range 2 {
+		token, err := Sign(signer, "client", "client", opts)
+		require.NoError(t, err)
+		sig, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+		require.NoError(t, err)
+		claims := new(struct {
+			JWTID string `json:"jti"`
+		})
+		require.NoError(t, json.Unmarshal(sig.UnsafePayloadWithoutVerification(), claims))
+		require.NotEmpty(t, claims.JWTID)
+		jtis[claims.JWTID] = true
+	}
+	assert.Len(t, jtis, 2, "jti must be unique")
+}
+
+func TestParseClaims(t *testing.T) {
+	dir := t.TempDir()
+	claimsFile := filepath.Join(dir, "claims.json")
+	require.NoError(t, os.WriteFile(claimsFile, []byte(`{"foo":"file","org":1}`), 0600))
+	nullFile := filepath.Join(dir, "null.json")
+	require.NoError(t, os.WriteFile(nullFile, []byte(`null`), 0600))
+
+	tests := []struct {
+		name    string
+		pairs   []string
+		file    string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name: "none",
+			want: map[string]any{},
+		},
+		{
+			name:    "missing file",
+			file:    filepath.Join(dir, "foo.json"),
+			wantErr: true,
+		},
+		{
+			name:    "missing separator",
+			pairs:   []string{"foo"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid json value",
+			pairs:   []string{"foo:={"},
+			wantErr: true,
+		},
+		{
+			name:  "null file",
+			file:  nullFile,
+			pairs: []string{"foo=bar"},
+			want:  map[string]any{"foo": "bar"},
+		},
+		{
+			name:  "pairs overwrite file",
+			file:  claimsFile,
+			pairs: []string{"foo=bar", "id=123", "roles:=[\"admin\"]", "n:=1"},
+			want: map[string]any{
+				"foo":   "bar",
+				"org":   float64(1),
+				"id":    "123",
+				"roles": []any{"admin"},
+				"n":     float64(1),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseClaims(tt.pairs, tt.file)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package assertion
+
+import (
+	"os"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/spf13/pflag"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+// Flags are the command line flags of the commands which sign an assertion with a key.
+type Flags struct {
+	KeyPath    string
+	Audience   []string
+	Issuer     string
+	Lifetime   time.Duration
+	Skew       time.Duration
+	Claims     []string
+	ClaimsFile string
+	Algorithm  string
+	Purpose    string
+
+	flags *pflag.FlagSet
+}
+
+// Register adds the flags to the flag set.
+// The purpose flag is only added by [Flags.RegisterPurpose].
+func (f *Flags) Register(flags *pflag.FlagSet) {
+	f.RegisterKey(flags)
+	flags.StringVar(&f.Issuer, "issuer", "", "issuer of the JWT (e.g. userID / client_id; only needed when generating from a PEM or JWK private key)")
+	f.RegisterClaims(flags)
+}
+
+// RegisterKey adds only the flags selecting the signing key and algorithm,
+// for commands which sign other tokens than assertions with it.
+func (f *Flags) RegisterKey(flags *pflag.FlagSet) {
+	flags.StringVar(&f.KeyPath, "key", "", "key.json or RSA, ECDSA or Ed25519 private key as PEM or JWK; a path, - for stdin, env:VAR or fd:N")
+	flags.StringVar(&f.Algorithm, "alg", "", "signature algorithm (e.g. RS256, PS256, ES256, ES384, EdDSA); picked from the key type if empty")
+}
+
+// RegisterClaims adds only the flags defining the claims of the assertion,
+// for commands which don't sign it with a key.
+func (f *Flags) RegisterClaims(flags *pflag.FlagSet) {
+	f.flags = flags
+	flags.StringSliceVar(&f.Audience, "audience", nil, "audience where the token will be used (e.g. the issuer of zitadel.cloud - https://zitadel.cloud or from your domain https://<your domain>); can be repeated")
+	flags.DurationVar(&f.Lifetime, "lifetime", DefaultLifetime, "duration after which the jwt expires")
+	flags.DurationVar(&f.Skew, "skew", 0, "backdate the iat and nbf claims by this duration to tolerate clock drift")
+	flags.StringArrayVar(&f.Claims, "claim", nil, "additional claim as key=value (string) or key:=json (e.g. number or array); can be repeated")
+	flags.StringVar(&f.ClaimsFile, "claims-file", "", "path to a JSON object with additional claims; overwritten by --claim")
+}
+
+// RegisterPurpose adds the purpose flag to the flag set,
+// for commands which print the assertion instead of using it as grant.
+func (f *Flags) RegisterPurpose(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Purpose, "purpose", PurposeGrant, "purpose of the jwt: grant for the JWT profile grant or client-assertion for private_key_jwt client authentication of applications (issued with a jti and a default lifetime of 5m)")
+}
+
+// Options validates the flags and returns the resulting assertion options and signature algorithm.
+func (f *Flags) Options() (*Options, jose.SignatureAlgorithm, error) {
+	alg, err := key.ParseAlgorithm(f.Algorithm)
+	if err != nil {
+		return nil, "", err
+	}
+	claims, err := ParseClaims(f.Claims, f.ClaimsFile)
+	if err != nil {
+		return nil, "", err
+	}
+	opts := &Options{
+		Audience: f.Audience,
+		Lifetime: f.Lifetime,
+		Skew:     f.Skew,
+		Claims:   claims,
+		Purpose:  f.Purpose,
+	}
+	if f.Purpose == PurposeClientAssertion && (f.flags == nil || !f.flags.Changed("lifetime")) {
+		opts.Lifetime = DefaultClientAssertionLifetime
+	}
+	return opts, alg, opts.Validate()
+}
+
+// Generate validates the flags, reads the key and returns the signed assertion.
+func (f *Flags) Generate() (string, error) {
+	if _, _, err := f.Options(); err != nil {
+		return "", err
+	}
+	data, err := key.Read(f.KeyPath, os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return f.Sign(data)
+}
+
+// Sign validates the flags and returns an assertion signed with the key data,
+// e.g. to sign a new assertion for every request with a key read only once.
+func (f *Flags) Sign(data []byte) (string, error) {
+	opts, alg, err := f.Options()
+	if err != nil {
+		return "", err
+	}
+	return FromKey(data, f.Issuer, alg, opts)
+}
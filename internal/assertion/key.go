@@ -0,0 +1,53 @@
+package assertion
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+// FromKey signs an assertion with the private key in data, which is detected by [key.LoadPrivateKey].
+// For a ZITADEL key file (key.json) the assertion is issued for the user or client of the key,
+// for a PEM or JWK private key it is issued for issuer.
+// If alg is empty, it is taken from a JWK or picked from the type of the private key.
+// Client assertions can only be signed with application keys.
+func FromKey(data []byte, issuer string, alg jose.SignatureAlgorithm, opts *Options) (string, error) {
+	privateKey, err := key.LoadPrivateKey(data)
+	if err != nil {
+		return "", err
+	}
+	if file := privateKey.File; file != nil {
+		switch file.Type {
+		case key.TypeApplication, key.TypeServiceAccount:
+		default:
+			return "", fmt.Errorf("unsupported key type %q", file.Type)
+		}
+		if opts.Purpose == PurposeClientAssertion && file.Type != key.TypeApplication {
+			return "", fmt.Errorf("client assertions require an %s key, got %q", key.TypeApplication, file.Type)
+		}
+		issuer = file.Subject()
+	} else if issuer == "" {
+		return "", errors.New("please provide the issuer of token when using a pem or jwk key")
+	}
+	if alg == "" {
+		alg = privateKey.Algorithm
+	}
+	signer, err := key.NewSigner(privateKey.Signer, privateKey.KeyID, alg)
+	if err != nil {
+		return "", err
+	}
+	return Sign(signer, issuer, issuer, opts)
+}
+
+// ClientAssertion signs a client assertion for the private_key_jwt authentication of the application key in data.
+// The audience is the issuer of the ZITADEL instance, clientID is only needed for a PEM or JWK private key.
+func ClientAssertion(data []byte, clientID, audience string) (string, error) {
+	return FromKey(data, clientID, "", &Options{
+		Audience: []string{audience},
+		Lifetime: DefaultClientAssertionLifetime,
+		Purpose:  PurposeClientAssertion,
+	})
+}
@@ -0,0 +1,99 @@
+package assertion
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+func TestFromKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privatePEM, err := key.EncodePrivateKey(privateKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		file    key.File
+		data    []byte
+		issuer  string
+		purpose string
+		wantSub string
+		wantKID string
+		wantErr bool
+	}{
+		{
+			name:    "pem without issuer",
+			data:    privatePEM,
+			wantErr: true,
+		},
+		{
+			name:    "pem with issuer",
+			data:    privatePEM,
+			issuer:  "client",
+			purpose: PurposeClientAssertion,
+			wantSub: "client",
+		},
+		{
+			name:    "unsupported type",
+			file:    key.File{Type: "foo", KeyID: "kid", Key: string(privatePEM)},
+			wantErr: true,
+		},
+		{
+			name:    "serviceaccount grant",
+			file:    key.File{Type: key.TypeServiceAccount, KeyID: "kid", Key: string(privatePEM), UserID: "user"},
+			purpose: PurposeGrant,
+			wantSub: "user",
+			wantKID: "kid",
+		},
+		{
+			name:    "serviceaccount client assertion",
+			file:    key.File{Type: key.TypeServiceAccount, KeyID: "kid", Key: string(privatePEM), UserID: "user"},
+			purpose: PurposeClientAssertion,
+			wantErr: true,
+		},
+		{
+			name:    "application client assertion",
+			file:    key.File{Type: key.TypeApplication, KeyID: "kid", Key: string(privatePEM), ClientID: "client"},
+			purpose: PurposeClientAssertion,
+			wantSub: "client",
+			wantKID: "kid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data
+			if data == nil {
+				data, err = json.Marshal(&tt.file)
+				require.NoError(t, err)
+			}
+			token, err := FromKey(data, tt.issuer, "", &Options{
+				Audience: []string{"https://example.com"},
+				Lifetime: DefaultClientAssertionLifetime,
+				Purpose:  tt.purpose,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			sig, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKID, sig.Signatures[0].Header.KeyID)
+			claims := new(struct {
+				Issuer  string `json:"iss"`
+				Subject string `json:"sub"`
+			})
+			require.NoError(t, json.Unmarshal(sig.UnsafePayloadWithoutVerification(), claims))
+			assert.Equal(t, tt.wantSub, claims.Issuer)
+			assert.Equal(t, tt.wantSub, claims.Subject)
+		})
+	}
+}
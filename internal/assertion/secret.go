@@ -0,0 +1,49 @@
+package assertion
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// HMACAlgorithms are the algorithms supported for signing client assertions with a client secret.
+var HMACAlgorithms = []jose.SignatureAlgorithm{jose.HS256, jose.HS384, jose.HS512}
+
+// FromSecret signs a client assertion for the client_secret_jwt authentication method (RFC 7523, section 2.2)
+// with the client secret as HMAC key. The assertion is issued for the client ID.
+// If alg is empty, HS256 is used.
+func FromSecret(clientID, secret string, alg jose.SignatureAlgorithm, opts *Options) (string, error) {
+	if clientID == "" || secret == "" {
+		return "", errors.New("client ID and secret are required")
+	}
+	if alg == "" {
+		alg = jose.HS256
+	}
+	if !slices.Contains(HMACAlgorithms, alg) {
+		return "", fmt.Errorf("unsupported signature algorithm %q, must be one of %v", alg, HMACAlgorithms)
+	}
+	// RFC 7518, section 3.2 requires a key of at least the size of the hash
+	if bits := hmacKeyBits(alg); len(secret)*8 < bits {
+		return "", fmt.Errorf("client secret is too short for %s, which requires at least %d bytes", alg, bits/8)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: []byte(secret)}, &jose.SignerOptions{})
+	if err != nil {
+		return "", err
+	}
+	clientAssertion := *opts
+	clientAssertion.Purpose = PurposeClientAssertion
+	return Sign(signer, clientID, clientID, &clientAssertion)
+}
+
+func hmacKeyBits(alg jose.SignatureAlgorithm) int {
+	switch alg {
+	case jose.HS384:
+		return 384
+	case jose.HS512:
+		return 512
+	default:
+		return 256
+	}
+}
@@ -0,0 +1,71 @@
+package assertion
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSecret(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	opts := &Options{
+		Audience: []string{"https://example.com"},
+		Lifetime: DefaultClientAssertionLifetime,
+	}
+	tests := []struct {
+		name     string
+		clientID string
+		secret   string
+		alg      jose.SignatureAlgorithm
+		opts     *Options
+		wantAlg  jose.SignatureAlgorithm
+		wantErr  bool
+	}{
+		{name: "default", clientID: "client", secret: secret, opts: opts, wantAlg: jose.HS256},
+		{name: "HS512", clientID: "client", secret: secret, alg: jose.HS512, opts: opts, wantAlg: jose.HS512},
+		{name: "secret too short for HS384", clientID: "client", secret: secret[:40], alg: jose.HS384, opts: opts, wantErr: true},
+		{name: "RS256", clientID: "client", secret: secret, alg: jose.RS256, opts: opts, wantErr: true},
+		{name: "missing secret", clientID: "client", opts: opts, wantErr: true},
+		{name: "missing client", secret: secret, opts: opts, wantErr: true},
+		{
+			name:     "too long lifetime",
+			clientID: "client",
+			secret:   secret,
+			opts:     &Options{Audience: []string{"https://example.com"}, Lifetime: 2 * time.Hour},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := FromSecret(tt.clientID, tt.secret, tt.alg, tt.opts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			sig, err := jose.ParseSigned(token, HMACAlgorithms)
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.wantAlg), sig.Signatures[0].Header.Algorithm)
+			payload, err := sig.Verify([]byte(tt.secret))
+			require.NoError(t, err)
+			claims := new(struct {
+				Issuer   string   `json:"iss"`
+				Subject  string   `json:"sub"`
+				Audience []string `json:"aud"`
+				JWTID    string   `json:"jti"`
+				Expiry   int64    `json:"exp"`
+			})
+			require.NoError(t, json.Unmarshal(payload, claims))
+			assert.Equal(t, tt.clientID, claims.Issuer)
+			assert.Equal(t, tt.clientID, claims.Subject)
+			assert.Equal(t, tt.opts.Audience, claims.Audience)
+			assert.NotEmpty(t, claims.JWTID)
+			assert.InDelta(t, time.Now().Add(tt.opts.Lifetime).Unix(), claims.Expiry, 1)
+			assert.Empty(t, tt.opts.Purpose, "options of the caller must not be modified")
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package clientauth authenticates the applications calling the endpoints of ZITADEL
+// with their client ID and secret or an application key.
+package clientauth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/ref"
+	"github.com/zitadel/zitadel-tools/internal/secret"
+)
+
+// ErrMissing is returned by [Flags.Auth] if neither a client secret nor a key was provided.
+var ErrMissing = errors.New("please provide the client ID and secret or an application key")
+
+// Flags are the command line flags to authenticate an application:
+// --id with --secret or --secret-from for client_secret_basic, or an application key for private_key_jwt.
+type Flags struct {
+	ClientID string
+	KeyPath  string
+	Secret   secret.Flags
+
+	keyName string
+}
+
+// Register adds the flags to the flag set, the key flag with the name keyName.
+// The label describes the application in the help, e.g. "of the API".
+func (f *Flags) Register(flags *pflag.FlagSet, keyName, label string) {
+	f.keyName = keyName
+	flags.StringVar(&f.ClientID, "id", "", "client ID "+label)
+	f.Secret.Register(flags, "secret", "client secret "+label)
+	flags.StringVar(&f.KeyPath, keyName, "", "application key.json "+label+" (or its private key as PEM or JWK with --id) for private_key_jwt; a path, - for stdin, env:VAR or fd:N")
+}
+
+// Provided reports whether a client secret or key was passed,
+// so commands supporting public clients only send the client ID otherwise.
+func (f *Flags) Provided() bool {
+	return f.KeyPath != "" || f.Secret.Provided()
+}
+
+// Refs returns the references of the key and secret flags for [ref.CheckStdin].
+func (f *Flags) Refs() []ref.Flag {
+	return []ref.Flag{{Name: "--" + f.keyName, Ref: f.KeyPath}, f.Secret.Ref()}
+}
+
+// Auth returns the client authentication with a client assertion for audience, which is the issuer of the instance,
+// signed by the key, or with basic auth of the client ID and secret, which is prompted for if not provided.
+func (f *Flags) Auth(audience string, log io.Writer) (oauth.ClientAuth, error) {
+	if f.KeyPath == "" {
+		if f.ClientID == "" {
+			return nil, ErrMissing
+		}
+		clientSecret, err := f.Secret.Read(os.Stdin, log)
+		if err != nil {
+			return nil, err
+		}
+		return oauth.ClientSecretBasic(f.ClientID, clientSecret), nil
+	}
+	if f.Secret.Provided() {
+		return nil, fmt.Errorf("--%s can't be combined with a client secret", f.keyName)
+	}
+	data, err := key.Read(f.KeyPath, os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	clientAssertion, err := assertion.ClientAssertion(data, f.ClientID, audience)
+	if err != nil {
+		return nil, fmt.Errorf("client assertion: %w", err)
+	}
+	return oauth.PrivateKeyJWT(clientAssertion), nil
+}
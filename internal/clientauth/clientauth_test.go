@@ -0,0 +1,63 @@
+package clientauth
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/internal/secret"
+)
+
+func TestFlags_Auth(t *testing.T) {
+	keyPath := keytest.Write(t, t.TempDir(), "key.pem", []byte(keytest.PEM(t)))
+
+	tests := []struct {
+		name       string
+		flags      Flags
+		wantHeader string
+		wantForm   []string
+		wantErr    bool
+	}{
+		{
+			name:    "nothing",
+			wantErr: true,
+		},
+		{
+			name:    "key and secret",
+			flags:   Flags{ClientID: "client", KeyPath: keyPath, Secret: secret.Flags{Value: "secret"}, keyName: "key"},
+			wantErr: true,
+		},
+		{
+			name:       "secret",
+			flags:      Flags{ClientID: "client", Secret: secret.Flags{Value: "secret"}},
+			wantHeader: "Basic " + oauth.BasicAuth("client", "secret"),
+		},
+		{
+			name:     "key",
+			flags:    Flags{ClientID: "client", KeyPath: keyPath},
+			wantForm: []string{"client_assertion", "client_assertion_type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := tt.flags.Auth("https://zitadel.example.com", io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			header, form := make(http.Header), make(url.Values)
+			auth(header, form)
+			assert.Equal(t, tt.wantHeader, header.Get("Authorization"))
+			for _, name := range tt.wantForm {
+				assert.NotEmpty(t, form.Get(name), name)
+			}
+		})
+	}
+}
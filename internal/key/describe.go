@@ -0,0 +1,34 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+)
+
+// MinRSABits is the smallest RSA key size considered secure.
+const MinRSABits = 2048
+
+// Describe returns the type and size or curve of the public key, e.g. "RSA 2048" or "ECDSA P-256".
+// The error reports keys which are too weak or can't be used to sign JWTs.
+func Describe(key crypto.PublicKey) (string, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		bits := key.N.BitLen()
+		description := fmt.Sprintf("RSA %d", bits)
+		if bits < MinRSABits {
+			return description, fmt.Errorf("RSA key size %d is too small, must be at least %d", bits, MinRSABits)
+		}
+		return description, nil
+	case *ecdsa.PublicKey:
+		description := "ECDSA " + key.Curve.Params().Name
+		_, err := curveAlgorithm(key.Curve)
+		return description, err
+	case ed25519.PublicKey:
+		return "Ed25519", nil
+	default:
+		return "", ErrUnsupportedPublicKey
+	}
+}
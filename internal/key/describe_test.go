@@ -0,0 +1,52 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	require.NoError(t, err)
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		key     crypto.PublicKey
+		want    string
+		wantErr bool
+	}{
+		{name: "rsa", key: &rsaKey.PublicKey, want: "RSA 2048"},
+		{name: "weak rsa", key: &weakKey.PublicKey, want: "RSA 1024", wantErr: true},
+		{name: "P-384", key: &p384.PublicKey, want: "ECDSA P-384"},
+		{name: "P-224", key: &p224.PublicKey, want: "ECDSA P-224", wantErr: true},
+		{name: "ed25519", key: edKey, want: "Ed25519"},
+		{name: "unsupported", key: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Describe(tt.key)
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
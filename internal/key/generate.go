@@ -0,0 +1,82 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+const (
+	AlgorithmRSA = "rsa"
+	AlgorithmEC  = "ec"
+)
+
+// Generate creates a new RSA key with the given bits
+// or a new ECDSA key on the named curve (P-256, P-384 or P-521).
+func Generate(algorithm string, bits int, curve string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmRSA:
+		if bits != 2048 && bits != 3072 && bits != 4096 {
+			return nil, fmt.Errorf("unsupported RSA key size %d, must be 2048, 3072 or 4096", bits)
+		}
+		return rsa.GenerateKey(rand.Reader, bits)
+	case AlgorithmEC:
+		c, err := parseCurve(curve)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.GenerateKey(c, rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %q, must be %s or %s", algorithm, AlgorithmRSA, AlgorithmEC)
+	}
+}
+
+func parseCurve(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported curve %q, must be P-256, P-384 or P-521", name)
+	}
+}
+
+// EncodePrivateKey encodes the private key to PEM,
+// using PKCS#1 for RSA keys like the key files of ZITADEL, SEC1 for ECDSA and PKCS#8 for all other keys.
+func EncodePrivateKey(key crypto.Signer) ([]byte, error) {
+	var block *pem.Block
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("encode private key: %w", err)
+		}
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("encode private key: %w", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// EncodePublicKey encodes the public key to a PKIX PEM block, as expected by the key upload of ZITADEL.
+func EncodePublicKey(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("encode public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
@@ -0,0 +1,51 @@
+package key
+
+import (
+	"crypto"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// JWK converts the key in data, which can be a ZITADEL key file, a PEM encoded key or a JWK,
+// into a JSON Web Key for signature verification.
+// The kid is the keyId of the key file or the SHA-256 thumbprint (RFC 7638) for PEM keys.
+// The private parameters are only included if includePrivate is true,
+// in which case data must contain a private key.
+func JWK(data []byte, includePrivate bool) (jose.JSONWebKey, error) {
+	var jwk jose.JSONWebKey
+	if includePrivate {
+		privateKey, err := LoadPrivateKey(data)
+		if err != nil {
+			return jose.JSONWebKey{}, err
+		}
+		jwk = jose.JSONWebKey{Key: privateKey.Signer, KeyID: privateKey.KeyID, Algorithm: string(privateKey.Algorithm)}
+	} else {
+		keys, err := PublicKeys(data)
+		if err != nil {
+			return jose.JSONWebKey{}, err
+		}
+		if len(keys) != 1 {
+			return jose.JSONWebKey{}, fmt.Errorf("expected a single key, got %d", len(keys))
+		}
+		jwk = keys[0]
+	}
+
+	jwk.Use = "sig"
+	if jwk.Algorithm == "" {
+		alg, err := PublicKeyAlgorithm(jwk.Public().Key)
+		if err != nil {
+			return jose.JSONWebKey{}, err
+		}
+		jwk.Algorithm = string(alg)
+	}
+	if jwk.KeyID == "" {
+		thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+		if err != nil {
+			return jose.JSONWebKey{}, fmt.Errorf("jwk thumbprint: %w", err)
+		}
+		jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+	}
+	return jwk, nil
+}
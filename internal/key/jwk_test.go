@@ -0,0 +1,71 @@
+package key
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWK(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	privatePEM, err := EncodePrivateKey(privateKey)
+	require.NoError(t, err)
+	publicPEM, err := EncodePublicKey(privateKey.Public())
+	require.NoError(t, err)
+	keyFile, err := json.Marshal(&File{Type: TypeServiceAccount, KeyID: "kid", Key: string(privatePEM), UserID: "user"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		data           []byte
+		includePrivate bool
+		wantKID        string
+		wantErr        bool
+	}{
+		{
+			name:    "key file",
+			data:    keyFile,
+			wantKID: "kid",
+		},
+		{
+			name:           "key file with private parameters",
+			data:           keyFile,
+			includePrivate: true,
+			wantKID:        "kid",
+		},
+		{
+			name: "public pem",
+			data: publicPEM,
+		},
+		{
+			name:           "public pem with private parameters",
+			data:           publicPEM,
+			includePrivate: true,
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JWK(tt.data, tt.includePrivate)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ES384", got.Algorithm)
+			assert.Equal(t, "sig", got.Use)
+			assert.Equal(t, !tt.includePrivate, got.IsPublic())
+			if tt.wantKID != "" {
+				assert.Equal(t, tt.wantKID, got.KeyID)
+			} else {
+				assert.Len(t, got.KeyID, 43, "base64url encoded SHA-256 thumbprint")
+			}
+		})
+	}
+}
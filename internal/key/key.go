@@ -0,0 +1,208 @@
+// Package key provides loading of ZITADEL key files and PEM encoded keys.
+package key
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	TypeServiceAccount = "serviceaccount"
+	TypeApplication    = "application"
+)
+
+var (
+	ErrPEMDecode             = errors.New("no PEM block found")
+	ErrUnsupportedPrivateKey = errors.New("unsupported private key, must be RSA, ECDSA or Ed25519")
+	ErrUnsupportedPublicKey  = errors.New("unsupported public key, must be RSA, ECDSA or Ed25519")
+)
+
+// File is a key file as it is downloaded from ZITADEL (key.json).
+type File struct {
+	Type           string    `json:"type"` // serviceaccount or application
+	KeyID          string    `json:"keyId"`
+	Key            string    `json:"key"`
+	ExpirationDate time.Time `json:"expirationDate,omitzero"`
+
+	// serviceaccount
+	UserID string `json:"userId,omitempty"`
+
+	// application
+	ClientID string `json:"clientId,omitempty"`
+	AppID    string `json:"appId,omitempty"`
+}
+
+// ParseFile parses the content of a ZITADEL key file.
+func ParseFile(data []byte) (*File, error) {
+	file := new(File)
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("key file: %w", err)
+	}
+	return file, nil
+}
+
+// Subject returns the user ID of a serviceaccount key
+// or the client ID of an application key.
+func (f *File) Subject() string {
+	if f.Type == TypeApplication {
+		return f.ClientID
+	}
+	return f.UserID
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS#1 (RSA), SEC1 (ECDSA) or PKCS#8 (RSA, ECDSA, Ed25519) private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrPEMDecode
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("private key: %w", err)
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return key.(crypto.Signer), nil
+	default:
+		return nil, ErrUnsupportedPrivateKey
+	}
+}
+
+// ParsePublicKey parses a PEM encoded public key, certificate or private key
+// and returns its public part.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrPEMDecode
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("public key: %w", err)
+		}
+		return checkPublicKey(key)
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("public key: %w", err)
+		}
+		return key, nil
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate: %w", err)
+		}
+		return checkPublicKey(cert.PublicKey)
+	default:
+		key, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+		return key.Public(), nil
+	}
+}
+
+func checkPublicKey(key crypto.PublicKey) (crypto.PublicKey, error) {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, ErrUnsupportedPublicKey
+	}
+}
+
+// PublicKeys returns the public keys contained in data,
+// which can be a ZITADEL key file, a JWKS, a single JWK
+// or a PEM encoded key or certificate.
+func PublicKeys(data []byte) ([]jose.JSONWebKey, error) {
+	data = bytes.TrimSpace(data)
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJWKS:
+		set := new(jose.JSONWebKeySet)
+		if err := json.Unmarshal(data, set); err != nil {
+			return nil, fmt.Errorf("jwks: %w", err)
+		}
+		keys := make([]jose.JSONWebKey, len(set.Keys))
+		for i, k := range set.Keys {
+			keys[i] = k.Public()
+		}
+		return keys, nil
+	case FormatJWK:
+		jwk := new(jose.JSONWebKey)
+		if err := json.Unmarshal(data, jwk); err != nil {
+			return nil, fmt.Errorf("jwk: %w", err)
+		}
+		return []jose.JSONWebKey{jwk.Public()}, nil
+	case FormatKeyFile:
+		file, err := ParseFile(data)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := ParsePublicKey([]byte(file.Key))
+		if err != nil {
+			return nil, err
+		}
+		return []jose.JSONWebKey{{Key: pub, KeyID: file.KeyID}}, nil
+	default:
+		pub, err := ParsePublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+		return []jose.JSONWebKey{{Key: pub}}, nil
+	}
+}
+
+// Validate checks that the key file has the fields required by its type.
+// All problems found are returned joined.
+func (f *File) Validate() error {
+	var errs []error
+	required := func(name, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is missing", name))
+		}
+	}
+	unexpected := func(name, value string) {
+		if value != "" {
+			errs = append(errs, fmt.Errorf("%s is not expected in a %s key", name, f.Type))
+		}
+	}
+	required("keyId", f.KeyID)
+	required("key", f.Key)
+	switch f.Type {
+	case TypeServiceAccount:
+		required("userId", f.UserID)
+		unexpected("clientId", f.ClientID)
+		unexpected("appId", f.AppID)
+	case TypeApplication:
+		required("clientId", f.ClientID)
+		required("appId", f.AppID)
+		unexpected("userId", f.UserID)
+	case "":
+		errs = append(errs, errors.New("type is missing"))
+	default:
+		errs = append(errs, fmt.Errorf("unsupported type %q, must be %s or %s", f.Type, TypeServiceAccount, TypeApplication))
+	}
+	return errors.Join(errs...)
+}
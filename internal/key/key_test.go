@@ -0,0 +1,222 @@
+package key
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantSubject string
+		wantErr     bool
+	}{
+		{
+			name:    "invalid json",
+			data:    "foo",
+			wantErr: true,
+		},
+		{
+			name:        "serviceaccount",
+			data:        `{"type":"serviceaccount","keyId":"1","key":"k","userId":"user","expirationDate":"2030-01-01T00:00:00Z"}`,
+			wantSubject: "user",
+		},
+		{
+			name:        "application",
+			data:        `{"type":"application","keyId":"1","key":"k","clientId":"client","appId":"app"}`,
+			wantSubject: "client",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFile([]byte(tt.data))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSubject, got.Subject())
+		})
+	}
+}
+
+func TestFile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    File
+		wantErr string
+	}{
+		{
+			name: "serviceaccount",
+			file: File{Type: TypeServiceAccount, KeyID: "1", Key: "k", UserID: "user"},
+		},
+		{
+			name: "application",
+			file: File{Type: TypeApplication, KeyID: "1", Key: "k", ClientID: "client", AppID: "app"},
+		},
+		{
+			name:    "missing type",
+			file:    File{KeyID: "1", Key: "k"},
+			wantErr: "type is missing",
+		},
+		{
+			name:    "unsupported type",
+			file:    File{Type: "user", KeyID: "1", Key: "k"},
+			wantErr: `unsupported type "user", must be serviceaccount or application`,
+		},
+		{
+			name:    "serviceaccount with application fields",
+			file:    File{Type: TypeServiceAccount, Key: "k", UserID: "user", ClientID: "client"},
+			wantErr: "keyId is missing\nclientId is not expected in a serviceaccount key",
+		},
+		{
+			name:    "application without client",
+			file:    File{Type: TypeApplication, KeyID: "1", AppID: "app"},
+			wantErr: "key is missing\nclientId is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.file.Validate()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		block   *pem.Block
+		wantErr bool
+	}{
+		{name: "pkcs1", block: &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}},
+		{name: "sec1", block: &pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}},
+		{name: "pkcs8", block: &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}},
+		{name: "garbage", block: &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("foo")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePrivateKey(pem.EncodeToMemory(tt.block))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPublicKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	ecPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER})
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+
+	keyFile, err := json.Marshal(&File{Type: TypeServiceAccount, KeyID: "kid", Key: string(rsaPEM), UserID: "user"})
+	require.NoError(t, err)
+	jwk, err := json.Marshal(jose.JSONWebKey{Key: rsaKey, KeyID: "private"})
+	require.NoError(t, err)
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &rsaKey.PublicKey, KeyID: "rsa"},
+		{Key: &ecKey.PublicKey, KeyID: "ec"},
+	}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantKIDs []string
+		wantErr  bool
+	}{
+		{
+			name:    "garbage",
+			data:    []byte("foo"),
+			wantErr: true,
+		},
+		{
+			name:    "key file without key",
+			data:    []byte(`{"type":"serviceaccount"}`),
+			wantErr: true,
+		},
+		{
+			name:     "key file",
+			data:     keyFile,
+			wantKIDs: []string{"kid"},
+		},
+		{
+			name:     "rsa private pem",
+			data:     rsaPEM,
+			wantKIDs: []string{""},
+		},
+		{
+			name:     "ec private pem",
+			data:     ecPEM,
+			wantKIDs: []string{""},
+		},
+		{
+			name:     "public pem",
+			data:     pubPEM,
+			wantKIDs: []string{""},
+		},
+		{
+			name:     "private jwk",
+			data:     jwk,
+			wantKIDs: []string{"private"},
+		},
+		{
+			name:     "jwks",
+			data:     jwks,
+			wantKIDs: []string{"rsa", "ec"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PublicKeys(tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			kids := make([]string, len(got))
+			for i, k := range got {
+				assert.True(t, k.IsPublic())
+				kids[i] = k.KeyID
+			}
+			assert.Equal(t, tt.wantKIDs, kids)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package key
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-jose/go-jose/v4"
+
+	"github.com/zitadel/zitadel-tools/internal/ref"
+)
+
+// Format is the format of a key, as detected by [DetectFormat].
+type Format string
+
+const (
+	FormatKeyFile Format = "key file"
+	FormatPEM     Format = "PEM"
+	FormatJWK     Format = "JWK"
+	FormatJWKS    Format = "JWKS"
+)
+
+var ErrUnknownFormat = errors.New("unknown key format, must be a ZITADEL key file, PEM, JWK or JWKS")
+
+// Read returns the content of the key referenced by keyRef, which is one of
+// "-" for stdin, "env:VAR", "fd:N" or a path, see [ref.Read].
+func Read(keyRef string, stdin io.Reader) ([]byte, error) {
+	if keyRef == "" {
+		return nil, errors.New("no key provided")
+	}
+	data, err := ref.Read(keyRef, stdin)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	return data, nil
+}
+
+// DetectFormat detects the format of the key from its content.
+func DetectFormat(data []byte) (Format, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("-----BEGIN ")) {
+		return FormatPEM, nil
+	}
+	if !json.Valid(data) {
+		return "", ErrUnknownFormat
+	}
+	probe := new(struct {
+		Keys json.RawMessage `json:"keys"`
+		Kty  string          `json:"kty"`
+		Type string          `json:"type"`
+		Key  string          `json:"key"`
+	})
+	if err := json.Unmarshal(data, probe); err != nil {
+		return "", ErrUnknownFormat
+	}
+	switch {
+	case probe.Keys != nil:
+		return FormatJWKS, nil
+	case probe.Kty != "":
+		return FormatJWK, nil
+	case probe.Type != "" || probe.Key != "":
+		return FormatKeyFile, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// PrivateKey is a private key loaded by [LoadPrivateKey].
+type PrivateKey struct {
+	Signer crypto.Signer
+	// KeyID is the keyId of a key file or the kid of a JWK.
+	KeyID string
+	// Algorithm is the alg of a JWK, if set.
+	Algorithm jose.SignatureAlgorithm
+	// File is the ZITADEL key file the key was loaded from, if any.
+	File *File
+}
+
+// LoadPrivateKey loads the private key from a ZITADEL key file, a PEM block or a JWK.
+func LoadPrivateKey(data []byte) (*PrivateKey, error) {
+	data = bytes.TrimSpace(data)
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatKeyFile:
+		file, err := ParseFile(data)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ParsePrivateKey([]byte(file.Key))
+		if err != nil {
+			return nil, err
+		}
+		return &PrivateKey{Signer: signer, KeyID: file.KeyID, File: file}, nil
+	case FormatPEM:
+		signer, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+		return &PrivateKey{Signer: signer}, nil
+	case FormatJWK:
+		jwk := new(jose.JSONWebKey)
+		if err = json.Unmarshal(data, jwk); err != nil {
+			return nil, fmt.Errorf("jwk: %w", err)
+		}
+		signer, ok := jwk.Key.(crypto.Signer)
+		if !ok || jwk.IsPublic() {
+			return nil, errors.New("jwk does not contain a private RSA, ECDSA or Ed25519 key")
+		}
+		return &PrivateKey{Signer: signer, KeyID: jwk.KeyID, Algorithm: jose.SignatureAlgorithm(jwk.Algorithm)}, nil
+	default:
+		return nil, fmt.Errorf("a %s does not contain a single private key", format)
+	}
+}
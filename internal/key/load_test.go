@@ -0,0 +1,118 @@
+package key
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("from file"), 0600))
+
+	got, err := Read(path, strings.NewReader("from stdin"))
+	require.NoError(t, err)
+	assert.Equal(t, "from file", string(got))
+	_, err = Read("", strings.NewReader("from stdin"))
+	assert.EqualError(t, err, "no key provided")
+	_, err = Read(path+".missing", strings.NewReader("from stdin"))
+	assert.ErrorContains(t, err, "read key: ")
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privatePEM, err := EncodePrivateKey(privateKey)
+	require.NoError(t, err)
+	publicPEM, err := EncodePublicKey(privateKey.Public())
+	require.NoError(t, err)
+	keyFile, err := json.Marshal(&File{Type: TypeApplication, KeyID: "file", Key: string(privatePEM), ClientID: "client"})
+	require.NoError(t, err)
+	privateJWK, err := json.Marshal(jose.JSONWebKey{Key: privateKey, KeyID: "jwk", Algorithm: "ES256"})
+	require.NoError(t, err)
+	publicJWK, err := json.Marshal(jose.JSONWebKey{Key: privateKey.Public(), KeyID: "jwk"})
+	require.NoError(t, err)
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: privateKey, KeyID: "jwk"}}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFormat Format
+		wantKeyID  string
+		wantFile   bool
+		wantErr    bool
+	}{
+		{
+			name:    "garbage",
+			data:    []byte("foo"),
+			wantErr: true,
+		},
+		{
+			name:    "unknown json",
+			data:    []byte(`{"foo":"bar"}`),
+			wantErr: true,
+		},
+		{
+			name:       "key file",
+			data:       keyFile,
+			wantFormat: FormatKeyFile,
+			wantKeyID:  "file",
+			wantFile:   true,
+		},
+		{
+			name:       "pem with surrounding whitespace",
+			data:       append([]byte("\n  "), privatePEM...),
+			wantFormat: FormatPEM,
+		},
+		{
+			name:       "public pem",
+			data:       publicPEM,
+			wantFormat: FormatPEM,
+			wantErr:    true,
+		},
+		{
+			name:       "private jwk",
+			data:       privateJWK,
+			wantFormat: FormatJWK,
+			wantKeyID:  "jwk",
+		},
+		{
+			name:       "public jwk",
+			data:       publicJWK,
+			wantFormat: FormatJWK,
+			wantErr:    true,
+		},
+		{
+			name:       "jwks",
+			data:       jwks,
+			wantFormat: FormatJWKS,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, _ := DetectFormat(tt.data)
+			assert.Equal(t, tt.wantFormat, format)
+
+			got, err := LoadPrivateKey(tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKeyID, got.KeyID)
+			assert.Equal(t, tt.wantFile, got.File != nil)
+			assert.True(t, privateKey.Equal(got.Signer))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// SignatureAlgorithms are the algorithms supported for signing with a private key.
+var SignatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// ParseAlgorithm returns the signature algorithm named alg,
+// or an empty algorithm if alg is empty.
+func ParseAlgorithm(alg string) (jose.SignatureAlgorithm, error) {
+	if alg == "" {
+		return "", nil
+	}
+	algorithm := jose.SignatureAlgorithm(alg)
+	if !slices.Contains(SignatureAlgorithms, algorithm) {
+		return "", fmt.Errorf("unsupported signature algorithm %q, must be one of %v", alg, SignatureAlgorithms)
+	}
+	return algorithm, nil
+}
+
+// Algorithm returns the default signature algorithm for the private key:
+// RS256 for RSA, ES256, ES384 or ES512 for the ECDSA curves P-256, P-384 and P-521
+// and EdDSA for Ed25519.
+func Algorithm(key crypto.Signer) (jose.SignatureAlgorithm, error) {
+	alg, err := PublicKeyAlgorithm(key.Public())
+	if errors.Is(err, ErrUnsupportedPublicKey) {
+		return "", ErrUnsupportedPrivateKey
+	}
+	return alg, err
+}
+
+// PublicKeyAlgorithm returns the default signature algorithm for the public key like [Algorithm].
+func PublicKeyAlgorithm(key crypto.PublicKey) (jose.SignatureAlgorithm, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return jose.RS256, nil
+	case *ecdsa.PublicKey:
+		return curveAlgorithm(key.Curve)
+	case ed25519.PublicKey:
+		return jose.EdDSA, nil
+	default:
+		return "", ErrUnsupportedPublicKey
+	}
+}
+
+func curveAlgorithm(curve elliptic.Curve) (jose.SignatureAlgorithm, error) {
+	switch curve {
+	case elliptic.P256():
+		return jose.ES256, nil
+	case elliptic.P384():
+		return jose.ES384, nil
+	case elliptic.P521():
+		return jose.ES512, nil
+	default:
+		return "", fmt.Errorf("unsupported curve %s, must be P-256, P-384 or P-521", curve.Params().Name)
+	}
+}
+
+// NewSigner returns a signer for the private key, which sets keyID as kid header if not empty.
+// If alg is empty, the algorithm is picked by [Algorithm],
+// otherwise it must be usable with the type (and curve) of the key.
+func NewSigner(key crypto.Signer, keyID string, alg jose.SignatureAlgorithm) (jose.Signer, error) {
+	detected, err := Algorithm(key)
+	if err != nil {
+		return nil, err
+	}
+	if alg == "" {
+		alg = detected
+	}
+	if !algorithmFitsKey(alg, detected) {
+		return nil, fmt.Errorf("signature algorithm %s can't be used with a %T, use %s instead", alg, key, detected)
+	}
+	signingKey := jose.SigningKey{
+		Algorithm: alg,
+		Key:       &jose.JSONWebKey{Key: key, KeyID: keyID},
+	}
+	return jose.NewSigner(signingKey, &jose.SignerOptions{})
+}
+
+func algorithmFitsKey(alg, detected jose.SignatureAlgorithm) bool {
+	switch detected {
+	case jose.RS256:
+		return slices.Contains([]jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512}, alg)
+	default:
+		return alg == detected
+	}
+}
@@ -0,0 +1,67 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		key     crypto.Signer
+		alg     jose.SignatureAlgorithm
+		wantAlg jose.SignatureAlgorithm
+		wantErr bool
+	}{
+		{name: "rsa default", key: rsaKey, wantAlg: jose.RS256},
+		{name: "rsa PS384", key: rsaKey, alg: jose.PS384, wantAlg: jose.PS384},
+		{name: "rsa ES256", key: rsaKey, alg: jose.ES256, wantErr: true},
+		{name: "P-256 default", key: p256, wantAlg: jose.ES256},
+		{name: "P-384 default", key: p384, wantAlg: jose.ES384},
+		{name: "P-521 default", key: p521, wantAlg: jose.ES512},
+		{name: "P-384 ES256", key: p384, alg: jose.ES256, wantErr: true},
+		{name: "ed25519 default", key: edKey, wantAlg: jose.EdDSA},
+		{name: "ed25519 RS256", key: edKey, alg: jose.RS256, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewSigner(tt.key, "kid", tt.alg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			sig, err := signer.Sign([]byte("payload"))
+			require.NoError(t, err)
+			compact, err := sig.CompactSerialize()
+			require.NoError(t, err)
+
+			parsed, err := jose.ParseSigned(compact, SignatureAlgorithms)
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.wantAlg), parsed.Signatures[0].Header.Algorithm)
+			assert.Equal(t, "kid", parsed.Signatures[0].Header.KeyID)
+			_, err = parsed.Verify(tt.key.Public())
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Package keytest creates the key files used as fixtures by the tests.
+package keytest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+// PEM returns a new 2048 bit RSA private key as PEM.
+func PEM(tb testing.TB) string {
+	tb.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatalf("generate key: %v", err)
+	}
+	data, err := key.EncodePrivateKey(privateKey)
+	if err != nil {
+		tb.Fatalf("encode key: %v", err)
+	}
+	return string(data)
+}
+
+// File returns file as key.json, with a new private key (see [PEM]) if its key is empty.
+func File(tb testing.TB, file key.File) []byte {
+	tb.Helper()
+	if file.Key == "" {
+		file.Key = PEM(tb)
+	}
+	data, err := json.Marshal(&file)
+	if err != nil {
+		tb.Fatalf("marshal key file: %v", err)
+	}
+	return data
+}
+
+// Write writes data to the file name in dir, e.g. a temporary directory of the test, and returns its path.
+func Write(tb testing.TB, dir, name string, data []byte) string {
+	tb.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		tb.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
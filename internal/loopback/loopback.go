@@ -0,0 +1,29 @@
+// Package loopback restricts local servers to the loopback interface.
+package loopback
+
+import (
+	"fmt"
+	"net"
+)
+
+// CheckAddress ensures that addr (host:port) is a loopback address,
+// so a server listening on it is only reachable from the local machine.
+func CheckAddress(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address: %w", err)
+	}
+	if !IsHost(host) {
+		return fmt.Errorf("listen address %s is not a loopback address", addr)
+	}
+	return nil
+}
+
+// IsHost reports whether host is localhost or a loopback IP.
+func IsHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
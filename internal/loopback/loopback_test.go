@@ -0,0 +1,32 @@
+package loopback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{addr: "127.0.0.1:9080"},
+		{addr: "[::1]:9080"},
+		{addr: "localhost:0"},
+		{addr: ":9080", wantErr: true},
+		{addr: "0.0.0.0:9080", wantErr: true},
+		{addr: "192.168.1.1:9080", wantErr: true},
+		{addr: "127.0.0.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			err := CheckAddress(tt.addr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+const (
+	AuthMethodBasic         = "client_secret_basic"
+	AuthMethodPost          = "client_secret_post"
+	AuthMethodPrivateKeyJWT = "private_key_jwt"
+)
+
+// ClientAuth authenticates a client at an endpoint
+// by setting the request header or form values.
+type ClientAuth func(header http.Header, form url.Values)
+
+// BasicAuth encodes the client ID and secret for the Authorization header of client_secret_basic.
+// Both are form-encoded before they are joined and base64 encoded, as required by RFC 6749, section 2.3.1.
+func BasicAuth(clientID, clientSecret string) string {
+	escaped := url.QueryEscape(clientID) + ":" + url.QueryEscape(clientSecret)
+	return base64.StdEncoding.EncodeToString([]byte(escaped))
+}
+
+// BasicCredentials are the client ID and secret decoded by [ParseBasicAuth].
+type BasicCredentials struct {
+	ClientID     string
+	ClientSecret string
+	// NotFormEncoded reports that the ID or secret contain characters which are not allowed in form-encoded values.
+	// Such a value is returned as it was sent, while ZITADEL decodes it into a different value or rejects it.
+	NotFormEncoded bool
+}
+
+// ParseBasicAuth reverses [BasicAuth]. The value is an Authorization header ("Authorization: Basic ..."),
+// its value ("Basic ...") or only the base64 encoded credentials.
+func ParseBasicAuth(value string) (*BasicCredentials, error) {
+	value = strings.TrimSpace(value)
+	if name, header, ok := strings.Cut(value, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Authorization") {
+		value = strings.TrimSpace(header)
+	}
+	if scheme, credentials, ok := strings.Cut(value, " "); ok {
+		if !strings.EqualFold(scheme, "Basic") {
+			return nil, fmt.Errorf("unsupported authorization scheme %q, must be Basic", scheme)
+		}
+		value = strings.TrimSpace(credentials)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("credentials are not base64 encoded: %w", err)
+	}
+	id, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil, errors.New("decoded credentials contain no colon separating client ID and secret")
+	}
+	credentials := new(BasicCredentials)
+	var idEncoded, secretEncoded bool
+	credentials.ClientID, idEncoded = formDecode(id)
+	credentials.ClientSecret, secretEncoded = formDecode(secret)
+	credentials.NotFormEncoded = !idEncoded || !secretEncoded
+	return credentials, nil
+}
+
+// formDecode reverses [url.QueryEscape] and reports whether s was form-encoded.
+// If not, s is returned unchanged.
+func formDecode(s string) (string, bool) {
+	for _, c := range []byte(s) {
+		if !isFormEncodedByte(c) {
+			return s, false
+		}
+	}
+	decoded, err := url.QueryUnescape(s)
+	if err != nil {
+		return s, false
+	}
+	return decoded, true
+}
+
+// isFormEncodedByte reports whether c may appear in a value encoded by [url.QueryEscape].
+func isFormEncodedByte(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-_.~%+", c) >= 0
+}
+
+// ClientSecretBasic authenticates the client with the Authorization header.
+func ClientSecretBasic(clientID, clientSecret string) ClientAuth {
+	return func(header http.Header, _ url.Values) {
+		header.Set("Authorization", "Basic "+BasicAuth(clientID, clientSecret))
+	}
+}
+
+// ClientSecretPost authenticates the client with the client_id and client_secret form values.
+func ClientSecretPost(clientID, clientSecret string) ClientAuth {
+	return func(_ http.Header, form url.Values) {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+	}
+}
+
+// PrivateKeyJWT authenticates the client with a signed client assertion (RFC 7523, section 2.2).
+func PrivateKeyJWT(clientAssertion string) ClientAuth {
+	return func(_ http.Header, form url.Values) {
+		form.Set("client_assertion_type", oidc.ClientAssertionTypeJWTAssertion)
+		form.Set("client_assertion", clientAssertion)
+	}
+}
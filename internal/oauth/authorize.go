@@ -0,0 +1,89 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CodeChallengeMethodS256 is the only PKCE method generated by [NewPKCE].
+const CodeChallengeMethodS256 = "S256"
+
+// PKCE is a code verifier and its challenge for Proof Key for Code Exchange (RFC 7636).
+type PKCE struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// NewPKCE generates a random code verifier with its S256 code challenge.
+func NewPKCE() (*PKCE, error) {
+	verifier, err := RandomValue()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(verifier))
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(hash[:]),
+		Method:    CodeChallengeMethodS256,
+	}, nil
+}
+
+// RandomValue returns 32 random bytes encoded as base64url, e.g. for state, nonce or a code verifier.
+func RandomValue() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", fmt.Errorf("generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// AuthorizeRequest are the parameters of an authorization code request with PKCE.
+type AuthorizeRequest struct {
+	ClientID    string
+	RedirectURI string
+	Scopes      []string
+	State       string
+	Nonce       string
+	PKCE        *PKCE
+	// Prompt and LoginHint are optional.
+	Prompt    string
+	LoginHint string
+}
+
+// AuthorizeURL returns the URL of the authorization endpoint with the request parameters.
+func AuthorizeURL(endpoint string, req *AuthorizeRequest) (string, error) {
+	if req.ClientID == "" || req.RedirectURI == "" {
+		return "", errors.New("client ID and redirect URI are required")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("authorization endpoint: %w", err)
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", req.ClientID)
+	query.Set("redirect_uri", req.RedirectURI)
+	query.Set("scope", strings.Join(req.Scopes, " "))
+	setIfNotEmpty(query, "state", req.State)
+	setIfNotEmpty(query, "nonce", req.Nonce)
+	if req.PKCE != nil {
+		query.Set("code_challenge", req.PKCE.Challenge)
+		query.Set("code_challenge_method", req.PKCE.Method)
+	}
+	setIfNotEmpty(query, "prompt", req.Prompt)
+	setIfNotEmpty(query, "login_hint", req.LoginHint)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func setIfNotEmpty(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
@@ -0,0 +1,54 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPKCE(t *testing.T) {
+	pkce, err := NewPKCE()
+	require.NoError(t, err)
+	assert.Len(t, pkce.Verifier, 43)
+	hash := sha256.Sum256([]byte(pkce.Verifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(hash[:]), pkce.Challenge)
+	assert.Equal(t, "S256", pkce.Method)
+
+	other, err := NewPKCE()
+	require.NoError(t, err)
+	assert.NotEqual(t, pkce.Verifier, other.Verifier)
+}
+
+func TestAuthorizeURL(t *testing.T) {
+	got, err := AuthorizeURL("https://example.com/oauth/v2/authorize", &AuthorizeRequest{
+		ClientID:    "client",
+		RedirectURI: "http://localhost:8080/callback",
+		Scopes:      []string{"openid", "profile"},
+		State:       "state",
+		Nonce:       "nonce",
+		PKCE:        &PKCE{Challenge: "challenge", Method: CodeChallengeMethodS256},
+		Prompt:      "login",
+	})
+	require.NoError(t, err)
+	u, err := url.Parse(got)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/oauth/v2/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"client"},
+		"redirect_uri":          {"http://localhost:8080/callback"},
+		"scope":                 {"openid profile"},
+		"state":                 {"state"},
+		"nonce":                 {"nonce"},
+		"code_challenge":        {"challenge"},
+		"code_challenge_method": {"S256"},
+		"prompt":                {"login"},
+	}, u.Query())
+
+	_, err = AuthorizeURL("https://example.com/oauth/v2/authorize", &AuthorizeRequest{ClientID: "client"})
+	assert.Error(t, err)
+}
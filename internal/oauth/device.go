@@ -0,0 +1,94 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+var (
+	// defaultDeviceInterval is the polling interval if the device authorization response contains none (RFC 8628, section 3.2).
+	defaultDeviceInterval = 5 * time.Second
+	// slowDownStep is added to the polling interval on each slow_down error (RFC 8628, section 3.5).
+	slowDownStep = 5 * time.Second
+)
+
+// ErrDeviceCodeExpired is returned by [PollDeviceToken] if the user did not approve the request in time.
+var ErrDeviceCodeExpired = errors.New("the device code expired before the request was approved, please start again")
+
+// DeviceAuthorization requests a device and user code for the client
+// with the device authorization grant (RFC 8628). Without auth, the client ID is sent as public client.
+func DeviceAuthorization(ctx context.Context, client *http.Client, endpoint, clientID string, scopes []string, auth ClientAuth) (*oidc.DeviceAuthorizationResponse, error) {
+	form := url.Values{}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	if auth == nil {
+		form.Set("client_id", clientID)
+	}
+	device := new(oidc.DeviceAuthorizationResponse)
+	if _, err := PostForm(ctx, client, endpoint, form, auth, device); err != nil {
+		return nil, err
+	}
+	if device.DeviceCode == "" || device.UserCode == "" || device.VerificationURI == "" {
+		return nil, errors.New("device authorization response is missing the device code, user code or verification URI")
+	}
+	return device, nil
+}
+
+// PollDeviceToken polls the token endpoint with the device code until the user approved the request.
+// It waits the interval of the response between the requests and increases it on slow_down errors.
+// It returns [ErrDeviceCodeExpired] if the code expired, or the error of the server if the user denied the request.
+func PollDeviceToken(ctx context.Context, client *http.Client, endpoint, clientID string, device *oidc.DeviceAuthorizationResponse, auth ClientAuth) (*TokenResponse, error) {
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceInterval
+	}
+	if device.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(device.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+	form := url.Values{
+		"grant_type":  {string(oidc.GrantTypeDeviceCode)},
+		"device_code": {device.DeviceCode},
+	}
+	if auth == nil {
+		form.Set("client_id", clientID)
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrDeviceCodeExpired
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		token, err := Token(ctx, client, endpoint, form, auth)
+		var oauthErr *Error
+		if !errors.As(err, &oauthErr) {
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrDeviceCodeExpired
+			}
+			return token, err
+		}
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownStep
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		default:
+			return nil, err
+		}
+		timer.Reset(interval)
+	}
+}
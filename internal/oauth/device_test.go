@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+func TestPollDeviceToken(t *testing.T) {
+	defaultDeviceInterval, slowDownStep = 10*time.Millisecond, 10*time.Millisecond
+
+	tests := []struct {
+		name      string
+		responses []string
+		expiresIn int
+		wantPolls int32
+		wantErr   bool
+		wantErrIs error
+	}{
+		{
+			name:      "approved",
+			responses: []string{"authorization_pending", "slow_down", "authorization_pending", ""},
+			wantPolls: 4,
+		},
+		{
+			name:      "denied",
+			responses: []string{"authorization_pending", "access_denied"},
+			wantPolls: 2,
+			wantErr:   true,
+		},
+		{
+			name:      "expired token",
+			responses: []string{"expired_token"},
+			wantPolls: 1,
+			wantErr:   true,
+			wantErrIs: ErrDeviceCodeExpired,
+		},
+		{
+			name:      "expired",
+			responses: []string{"authorization_pending"},
+			expiresIn: 1,
+			wantErr:   true,
+			wantErrIs: ErrDeviceCodeExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(polls.Add(1)) - 1
+				assert.Equal(t, string(oidc.GrantTypeDeviceCode), r.PostFormValue("grant_type"))
+				assert.Equal(t, "device", r.PostFormValue("device_code"))
+				assert.Equal(t, "client", r.PostFormValue("client_id"))
+				w.Header().Set("Content-Type", "application/json")
+				if code := tt.responses[min(n, len(tt.responses)-1)]; code != "" {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(map[string]string{"error": code})
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"access_token": "token", "token_type": "Bearer"})
+			}))
+			defer server.Close()
+
+			device := &oidc.DeviceAuthorizationResponse{DeviceCode: "device", ExpiresIn: tt.expiresIn}
+			got, err := PollDeviceToken(context.Background(), nil, server.URL, "client", device, nil)
+			if tt.wantPolls > 0 {
+				assert.Equal(t, tt.wantPolls, polls.Load())
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "token", got.AccessToken)
+		})
+	}
+}
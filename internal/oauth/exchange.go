@@ -0,0 +1,74 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+// tokenTypes are the short names of the token type identifiers accepted by [ParseTokenType].
+var tokenTypes = map[string]oidc.TokenType{
+	"access_token":  oidc.AccessTokenType,
+	"refresh_token": oidc.RefreshTokenType,
+	"id_token":      oidc.IDTokenType,
+	"jwt":           oidc.JWTTokenType,
+}
+
+// ParseTokenType returns the token type identifier (RFC 8693, section 3) for its short name,
+// e.g. access_token or jwt, or the identifier itself.
+func ParseTokenType(name string) (oidc.TokenType, error) {
+	if tokenType, ok := tokenTypes[name]; ok {
+		return tokenType, nil
+	}
+	if oidc.TokenType(name).IsSupported() {
+		return oidc.TokenType(name), nil
+	}
+	return "", fmt.Errorf("unsupported token type %q, must be access_token, refresh_token, id_token, jwt or a urn:ietf:params:oauth:token-type identifier", name)
+}
+
+// TokenExchangeRequest are the parameters of a token exchange (RFC 8693).
+type TokenExchangeRequest struct {
+	SubjectToken     string
+	SubjectTokenType oidc.TokenType
+	// ActorToken is the token of the party acting on behalf of the subject, e.g. for impersonation.
+	ActorToken         string
+	ActorTokenType     oidc.TokenType
+	RequestedTokenType oidc.TokenType
+	Audience           []string
+	Scopes             []string
+}
+
+// TokenExchange exchanges the subject token, and the actor token if set, for a new token
+// with the token exchange grant. The client is authenticated by auth.
+func TokenExchange(ctx context.Context, client *http.Client, endpoint string, req *TokenExchangeRequest, auth ClientAuth) (*TokenResponse, error) {
+	if req.SubjectToken == "" || req.SubjectTokenType == "" {
+		return nil, errors.New("subject token and its type are required")
+	}
+	if req.ActorToken != "" && req.ActorTokenType == "" {
+		return nil, errors.New("actor token type is required with an actor token")
+	}
+	form := url.Values{
+		"grant_type":         {string(oidc.GrantTypeTokenExchange)},
+		"subject_token":      {req.SubjectToken},
+		"subject_token_type": {string(req.SubjectTokenType)},
+	}
+	if req.ActorToken != "" {
+		form.Set("actor_token", req.ActorToken)
+		form.Set("actor_token_type", string(req.ActorTokenType))
+	}
+	if req.RequestedTokenType != "" {
+		form.Set("requested_token_type", string(req.RequestedTokenType))
+	}
+	for _, audience := range req.Audience {
+		form.Add("audience", audience)
+	}
+	if len(req.Scopes) > 0 {
+		form.Set("scope", strings.Join(req.Scopes, " "))
+	}
+	return Token(ctx, client, endpoint, form, auth)
+}
@@ -0,0 +1,74 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+func TestParseTokenType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    oidc.TokenType
+		wantErr bool
+	}{
+		{name: "access_token", want: oidc.AccessTokenType},
+		{name: "jwt", want: oidc.JWTTokenType},
+		{name: "urn:ietf:params:oauth:token-type:id_token", want: oidc.IDTokenType},
+		{name: "saml2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTokenType(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTokenExchange(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"exchanged","token_type":"Bearer","issued_token_type":"urn:ietf:params:oauth:token-type:jwt"}`))
+	}))
+	defer server.Close()
+
+	got, err := TokenExchange(context.Background(), nil, server.URL, &TokenExchangeRequest{
+		SubjectToken:       "subject",
+		SubjectTokenType:   oidc.AccessTokenType,
+		ActorToken:         "actor",
+		ActorTokenType:     oidc.JWTTokenType,
+		RequestedTokenType: oidc.JWTTokenType,
+		Audience:           []string{"a", "b"},
+		Scopes:             []string{"openid", "profile"},
+	}, ClientSecretBasic("client", "secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "exchanged", got.AccessToken)
+	assert.Equal(t, string(oidc.JWTTokenType), got.IssuedTokenType)
+	assert.Equal(t, url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"subject_token":        {"subject"},
+		"subject_token_type":   {"urn:ietf:params:oauth:token-type:access_token"},
+		"actor_token":          {"actor"},
+		"actor_token_type":     {"urn:ietf:params:oauth:token-type:jwt"},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:jwt"},
+		"audience":             {"a", "b"},
+		"scope":                {"openid profile"},
+	}, form)
+
+	_, err = TokenExchange(context.Background(), nil, server.URL, &TokenExchangeRequest{SubjectTokenType: oidc.AccessTokenType}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+// idTokenLeeway tolerates clock drift between the OP and the local machine when checking the expiry.
+const idTokenLeeway = time.Minute
+
+// IDTokenClaims are the claims of an ID token checked by [VerifyIDToken].
+type IDTokenClaims struct {
+	Issuer     string        `json:"iss"`
+	Subject    string        `json:"sub"`
+	Audience   oidc.Audience `json:"aud"`
+	Expiration oidc.Time     `json:"exp"`
+	Nonce      string        `json:"nonce"`
+}
+
+// FetchKeys requests the JSON Web Key Set of the OP at jwksURI.
+func FetchKeys(ctx context.Context, client *http.Client, jwksURI string) (*jose.JSONWebKeySet, error) {
+	keys := new(jose.JSONWebKeySet)
+	if err := Get(ctx, client, jwksURI, keys); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	return keys, nil
+}
+
+// VerifyIDToken verifies the signature of the ID token with keys
+// and checks that it was issued by issuer for clientID with the nonce of the authorization request and is not expired.
+func VerifyIDToken(idToken string, keys *jose.JSONWebKeySet, issuer, clientID, nonce string, now time.Time) (*IDTokenClaims, error) {
+	sig, err := jose.ParseSigned(idToken, key.SignatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+	candidates := keys.Keys
+	if kid := sig.Signatures[0].Header.KeyID; kid != "" {
+		candidates = keys.Key(kid)
+	}
+	var payload []byte
+	for _, k := range candidates {
+		if payload, err = sig.Verify(&k); err == nil {
+			break
+		}
+	}
+	if payload == nil {
+		return nil, errors.New("id token: signature does not match any key of the issuer")
+	}
+
+	claims := new(IDTokenClaims)
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+	switch {
+	case claims.Issuer != issuer:
+		return nil, fmt.Errorf("id token: issuer %q does not match %q", claims.Issuer, issuer)
+	case !slices.Contains(claims.Audience, clientID):
+		return nil, fmt.Errorf("id token: audience %v does not contain the client ID %q", []string(claims.Audience), clientID)
+	case claims.Nonce != nonce:
+		return nil, errors.New("id token: nonce does not match the authorization request")
+	case now.After(claims.Expiration.AsTime().Add(idTokenLeeway)):
+		return nil, fmt.Errorf("id token: expired at %s", claims.Expiration.AsTime().UTC().Format(time.RFC3339))
+	}
+	return claims, nil
+}
@@ -0,0 +1,103 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keys := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &privateKey.PublicKey, KeyID: "kid", Algorithm: "ES256"}}}
+	now := time.Now()
+	sign := func(signingKey *ecdsa.PrivateKey, claims map[string]any) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: signingKey}, (&jose.SignerOptions{}).WithHeader(jose.HeaderKey("kid"), "kid"))
+		require.NoError(t, err)
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+		signed, err := signer.Sign(payload)
+		require.NoError(t, err)
+		token, err := signed.CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+	claims := func(modify func(map[string]any)) map[string]any {
+		c := map[string]any{
+			"iss":   "https://issuer.example.com",
+			"sub":   "user",
+			"aud":   []string{"client", "project"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"nonce": "nonce",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		idToken string
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			idToken: sign(privateKey, claims(nil)),
+		},
+		{
+			name:    "expired within leeway",
+			idToken: sign(privateKey, claims(func(c map[string]any) { c["exp"] = now.Add(-30 * time.Second).Unix() })),
+		},
+		{
+			name:    "expired",
+			idToken: sign(privateKey, claims(func(c map[string]any) { c["exp"] = now.Add(-time.Hour).Unix() })),
+			wantErr: true,
+		},
+		{
+			name:    "other key",
+			idToken: sign(otherKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "other issuer",
+			idToken: sign(privateKey, claims(func(c map[string]any) { c["iss"] = "https://attacker.example.com" })),
+			wantErr: true,
+		},
+		{
+			name:    "other audience",
+			idToken: sign(privateKey, claims(func(c map[string]any) { c["aud"] = "other" })),
+			wantErr: true,
+		},
+		{
+			name:    "other nonce",
+			idToken: sign(privateKey, claims(func(c map[string]any) { c["nonce"] = "replayed" })),
+			wantErr: true,
+		},
+		{
+			name:    "not a jwt",
+			idToken: "token",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyIDToken(tt.idToken, keys, "https://issuer.example.com", "client", "nonce", now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user", got.Subject)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+// ClaimProjectRoles contains the granted roles of the projects in the audience,
+// mapped to the IDs and primary domains of the organizations they are granted in.
+const ClaimProjectRoles = "urn:zitadel:iam:org:project:roles"
+
+// projectRolesClaim matches the claims with the roles of a single project (urn:zitadel:iam:org:project:{id}:roles).
+var projectRolesClaim = regexp.MustCompile(`^urn:zitadel:iam:org:project:` + zitadelID + `:roles$`)
+
+// Roles maps role keys to the organizations (ID to primary domain) the role is granted in.
+type Roles map[string]map[string]string
+
+// Introspection is the response of the introspection endpoint (RFC 7662).
+type Introspection struct {
+	Active     bool                     `json:"active"`
+	Scope      oidc.SpaceDelimitedArray `json:"scope,omitempty"`
+	ClientID   string                   `json:"client_id,omitempty"`
+	TokenType  string                   `json:"token_type,omitempty"`
+	Subject    string                   `json:"sub,omitempty"`
+	Username   string                   `json:"username,omitempty"`
+	Audience   oidc.Audience            `json:"aud,omitempty"`
+	Issuer     string                   `json:"iss,omitempty"`
+	IssuedAt   oidc.Time                `json:"iat,omitempty"`
+	Expiration oidc.Time                `json:"exp,omitempty"`
+	// Roles are the roles of the ClaimProjectRoles claim merged with those of the project specific claims.
+	Roles Roles `json:"-"`
+
+	// Raw is the unmodified response body including all additional claims.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Introspect asks the introspection endpoint whether the token is active,
+// authenticated by auth as the client of the API.
+func Introspect(ctx context.Context, client *http.Client, endpoint, token string, auth ClientAuth) (*Introspection, error) {
+	introspection := new(Introspection)
+	raw, err := PostForm(ctx, client, endpoint, url.Values{"token": {token}}, auth, introspection)
+	if err != nil {
+		return nil, err
+	}
+	introspection.Raw = raw
+	if introspection.Roles, err = parseRoles(raw); err != nil {
+		return nil, err
+	}
+	return introspection, nil
+}
+
+// IndentedJSON returns the raw introspection response as indented JSON.
+func (i *Introspection) IndentedJSON() ([]byte, error) {
+	return indentJSON(i.Raw)
+}
+
+// parseRoles merges the roles of the ZITADEL role claims in the JSON object data.
+func parseRoles(data []byte) (Roles, error) {
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+	var roles Roles
+	for name, value := range claims {
+		if name != ClaimProjectRoles && !projectRolesClaim.MatchString(name) {
+			continue
+		}
+		var claim Roles
+		if err := json.Unmarshal(value, &claim); err != nil {
+			return nil, fmt.Errorf("%s claim: %w", name, err)
+		}
+		if roles == nil {
+			roles = make(Roles, len(claim))
+		}
+		for role, orgs := range claim {
+			if roles[role] == nil {
+				roles[role] = make(map[string]string, len(orgs))
+			}
+			maps.Copy(roles[role], orgs)
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,70 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospect(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *Introspection
+		wantErr bool
+	}{
+		{
+			name: "inactive",
+			body: `{"active":false}`,
+			want: &Introspection{},
+		},
+		{
+			name: "roles",
+			body: `{
+				"active": true,
+				"scope": "openid profile",
+				"sub": "user",
+				"urn:zitadel:iam:org:project:roles": {"admin": {"1": "acme.example.com"}},
+				"urn:zitadel:iam:org:project:123:roles": {"admin": {"2": "other.example.com"}, "viewer": {"1": "acme.example.com"}}
+			}`,
+			want: &Introspection{
+				Active:  true,
+				Scope:   []string{"openid", "profile"},
+				Subject: "user",
+				Roles: Roles{
+					"admin":  {"1": "acme.example.com", "2": "other.example.com"},
+					"viewer": {"1": "acme.example.com"},
+				},
+			},
+		},
+		{
+			name:    "invalid roles",
+			body:    `{"active":true,"urn:zitadel:iam:org:project:roles":["admin"]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "token", r.PostFormValue("token"))
+				assert.Equal(t, "Basic "+BasicAuth("api", "secret"), r.Header.Get("Authorization"))
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := Introspect(context.Background(), nil, server.URL, "token", ClientSecretBasic("api", "secret"))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			got.Raw = nil
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
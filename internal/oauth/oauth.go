@@ -0,0 +1,156 @@
+// Package oauth implements the OAuth 2.0 and OpenID Connect requests sent to ZITADEL.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+const (
+	// ScopeZITADELAudience adds the ZITADEL API project to the audience of the token.
+	ScopeZITADELAudience = "urn:zitadel:iam:org:project:id:zitadel:aud"
+
+	// maxResponseSize limits the size of the responses read from the server.
+	maxResponseSize = 1 << 20
+)
+
+// ProjectAudienceScope returns the scope which adds the project with projectID to the audience of the token.
+func ProjectAudienceScope(projectID string) string {
+	return "urn:zitadel:iam:org:project:id:" + projectID + ":aud"
+}
+
+// WithProjectAudiences returns the scopes extended by the [ProjectAudienceScope] of each project ID.
+func WithProjectAudiences(scopes, projectIDs []string) []string {
+	requested := make([]string, 0, len(scopes)+len(projectIDs))
+	requested = append(requested, scopes...)
+	for _, id := range projectIDs {
+		requested = append(requested, ProjectAudienceScope(id))
+	}
+	return requested
+}
+
+// Error is an error response of an OAuth 2.0 endpoint.
+type Error struct {
+	StatusCode  int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	if e.Description == "" {
+		return fmt.Sprintf("%s (status %d)", e.Code, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", e.Code, e.Description, e.StatusCode)
+}
+
+// DiscoveryURL returns the URL of the OpenID Connect discovery document of issuer.
+func DiscoveryURL(issuer string) string {
+	return strings.TrimSuffix(issuer, "/") + oidc.DiscoveryEndpoint
+}
+
+// Discover fetches the OpenID Connect discovery document of issuer
+// and checks that it belongs to the very same issuer.
+func Discover(ctx context.Context, client *http.Client, issuer string) (*oidc.DiscoveryConfiguration, error) {
+	config := new(oidc.DiscoveryConfiguration)
+	if err := Get(ctx, client, DiscoveryURL(issuer), config); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if config.Issuer != issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match the requested %q", config.Issuer, issuer)
+	}
+	return config, nil
+}
+
+// Get requests the JSON document at endpoint and decodes it into dst.
+func Get(ctx context.Context, client *http.Client, endpoint string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	_, err = do(client, req, dst)
+	return err
+}
+
+// PostForm sends the form to the endpoint, authenticated by auth if not nil,
+// and decodes the JSON response into dst.
+// It returns the raw response body.
+func PostForm(ctx context.Context, client *http.Client, endpoint string, form url.Values, auth ClientAuth, dst any) ([]byte, error) {
+	header := make(http.Header)
+	if auth != nil {
+		auth(header, form)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(client, req, dst)
+}
+
+// challengeParam matches the auth-params of a WWW-Authenticate challenge.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// bearerChallengeError returns the error and error_description of a Bearer challenge.
+func bearerChallengeError(challenge string) (code, description string) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", ""
+	}
+	for _, match := range challengeParam.FindAllStringSubmatch(params, -1) {
+		switch match[1] {
+		case "error":
+			code = match[2]
+		case "error_description":
+			description = match[2]
+		}
+	}
+	return code, description
+}
+
+func do(client *http.Client, req *http.Request, dst any) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		oauthErr := &Error{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(body, oauthErr)
+		if oauthErr.Code == "" {
+			// resource endpoints like userinfo return the error in the challenge (RFC 6750, section 3)
+			oauthErr.Code, oauthErr.Description = bearerChallengeError(resp.Header.Get("WWW-Authenticate"))
+		}
+		return body, oauthErr
+	}
+	if dst == nil {
+		return body, nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return body, fmt.Errorf("unexpected content type %q from %s", mediaType, req.URL)
+	}
+	if err = json.Unmarshal(body, dst); err != nil {
+		return body, fmt.Errorf("decode response: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,77 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostForm(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "oauth error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"invalid_client","error_description":"client not found"}`))
+			},
+			wantErr: "invalid_client: client not found (status 401)",
+		},
+		{
+			name: "bearer challenge",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="zitadel", error="invalid_token", error_description="token expired"`)
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			wantErr: "invalid_token: token expired (status 401)",
+		},
+		{
+			name: "plain error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "not found", http.StatusNotFound)
+			},
+			wantErr: "unexpected status 404",
+		},
+		{
+			name: "html instead of json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte(`<html></html>`))
+			},
+			wantErr: `unexpected content type "text/html"`,
+		},
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.Write([]byte(`{"foo":"` + r.PostFormValue("foo") + `"}`))
+			},
+			want: map[string]string{"foo": "bar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			var got map[string]string
+			_, err := PostForm(context.Background(), nil, server.URL, url.Values{"foo": {"bar"}}, nil, &got)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ScopeOrgIDPrefix restricts the login to the organization with the ID following the prefix.
+	ScopeOrgIDPrefix = "urn:zitadel:iam:org:id:"
+	// ScopeOrgDomainPrefix restricts the login to the organization with the primary domain following the prefix.
+	ScopeOrgDomainPrefix = "urn:zitadel:iam:org:domain:primary:"
+	// ScopeRolePrefix requests the role with the key following the prefix.
+	ScopeRolePrefix = "urn:zitadel:iam:org:project:role:"
+	// ScopeAllProjectRoles requests the roles of all projects in the audience.
+	ScopeAllProjectRoles = "urn:zitadel:iam:org:projects:roles"
+
+	reservedScopePrefix = "urn:zitadel:"
+)
+
+var (
+	zitadelID  = `[0-9A-Za-z_-]+`
+	domainName = `(?:[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)*[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?`
+
+	// reservedScopes are the syntax of the reserved scopes of ZITADEL.
+	reservedScopes = []struct {
+		pattern *regexp.Regexp
+		syntax  string
+	}{
+		{regexp.MustCompile(`^urn:zitadel:iam:org:id:` + zitadelID + `$`), "urn:zitadel:iam:org:id:{id}"},
+		{regexp.MustCompile(`^urn:zitadel:iam:org:domain:primary:` + domainName + `$`), "urn:zitadel:iam:org:domain:primary:{domain}"},
+		{regexp.MustCompile(`^urn:zitadel:iam:org:project:id:(?:` + zitadelID + `|zitadel):aud$`), "urn:zitadel:iam:org:project:id:{id}:aud"},
+		{regexp.MustCompile(`^urn:zitadel:iam:org:project:role:[^\s]+$`), "urn:zitadel:iam:org:project:role:{role key}"},
+		{regexp.MustCompile(`^urn:zitadel:iam:org:projects:roles$`), ScopeAllProjectRoles},
+		{regexp.MustCompile(`^urn:zitadel:iam:org:roles:id:` + zitadelID + `$`), "urn:zitadel:iam:org:roles:id:{id}"},
+		{regexp.MustCompile(`^urn:zitadel:iam:org:idp:id:` + zitadelID + `$`), "urn:zitadel:iam:org:idp:id:{id}"},
+		{regexp.MustCompile(`^urn:zitadel:iam:user:metadata$`), "urn:zitadel:iam:user:metadata"},
+		{regexp.MustCompile(`^urn:zitadel:iam:user:resourceowner$`), "urn:zitadel:iam:user:resourceowner"},
+	}
+)
+
+// OrgIDScope returns the scope restricting the login to the organization with orgID.
+func OrgIDScope(orgID string) string {
+	return ScopeOrgIDPrefix + orgID
+}
+
+// OrgDomainScope returns the scope restricting the login to the organization with the primary domain.
+func OrgDomainScope(domain string) string {
+	return ScopeOrgDomainPrefix + domain
+}
+
+// RoleScope returns the scope requesting the role with roleKey.
+func RoleScope(roleKey string) string {
+	return ScopeRolePrefix + roleKey
+}
+
+// ValidateScopes checks the syntax of the ZITADEL reserved scopes (urn:zitadel:...) and returns all problems joined.
+// Other scopes are not checked.
+func ValidateScopes(scopes []string) error {
+	var errs []error
+	var orgScopes []string
+	for _, scope := range scopes {
+		if strings.HasPrefix(scope, ScopeOrgIDPrefix) || strings.HasPrefix(scope, ScopeOrgDomainPrefix) {
+			orgScopes = append(orgScopes, scope)
+		}
+		if !strings.HasPrefix(scope, reservedScopePrefix) {
+			continue
+		}
+		if err := validateReservedScope(scope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(orgScopes) > 1 {
+		errs = append(errs, fmt.Errorf("only one organization can be requested, got %s", strings.Join(orgScopes, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+func validateReservedScope(scope string) error {
+	for _, reserved := range reservedScopes {
+		if reserved.pattern.MatchString(scope) {
+			return nil
+		}
+	}
+	// point to the reserved scope with the longest common prefix
+	var closest string
+	longest := 0
+	for _, reserved := range reservedScopes {
+		fixed, _, _ := strings.Cut(reserved.syntax, "{")
+		if n := commonPrefix(scope, fixed); n > longest {
+			longest, closest = n, reserved.syntax
+		}
+	}
+	if closest == "" || longest <= len(reservedScopePrefix+"iam:") {
+		return fmt.Errorf("unknown reserved scope %q", scope)
+	}
+	return fmt.Errorf("invalid reserved scope %q, expected %s", scope, closest)
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			scopes: []string{
+				"openid", "profile",
+				"urn:zitadel:iam:org:id:164849314865070081",
+				"urn:zitadel:iam:org:project:id:164849314865070082:aud",
+				"urn:zitadel:iam:org:project:id:zitadel:aud",
+				"urn:zitadel:iam:org:project:role:admin.read",
+				"urn:zitadel:iam:org:projects:roles",
+				"urn:zitadel:iam:user:metadata",
+			},
+		},
+		{
+			name:   "primary domain",
+			scopes: []string{"urn:zitadel:iam:org:domain:primary:acme.zitadel.cloud"},
+		},
+		{
+			name:    "project audience without id",
+			scopes:  []string{"urn:zitadel:iam:org:project:164849314865070082:aud"},
+			wantErr: `invalid reserved scope "urn:zitadel:iam:org:project:164849314865070082:aud", expected urn:zitadel:iam:org:project:id:{id}:aud`,
+		},
+		{
+			name:    "project audience without aud suffix",
+			scopes:  []string{"urn:zitadel:iam:org:project:id:164849314865070082"},
+			wantErr: `invalid reserved scope "urn:zitadel:iam:org:project:id:164849314865070082", expected urn:zitadel:iam:org:project:id:{id}:aud`,
+		},
+		{
+			name:    "empty org id",
+			scopes:  []string{"urn:zitadel:iam:org:id:"},
+			wantErr: `invalid reserved scope "urn:zitadel:iam:org:id:", expected urn:zitadel:iam:org:id:{id}`,
+		},
+		{
+			name:    "invalid domain",
+			scopes:  []string{"urn:zitadel:iam:org:domain:primary:https://acme.com"},
+			wantErr: `invalid reserved scope "urn:zitadel:iam:org:domain:primary:https://acme.com", expected urn:zitadel:iam:org:domain:primary:{domain}`,
+		},
+		{
+			name:    "unknown",
+			scopes:  []string{"urn:zitadel:foo"},
+			wantErr: `unknown reserved scope "urn:zitadel:foo"`,
+		},
+		{
+			name:    "multiple organizations",
+			scopes:  []string{"urn:zitadel:iam:org:id:1", "urn:zitadel:iam:org:domain:primary:acme.com"},
+			wantErr: "only one organization can be requested, got urn:zitadel:iam:org:id:1, urn:zitadel:iam:org:domain:primary:acme.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateScopes(tt.scopes)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
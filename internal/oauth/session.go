@@ -0,0 +1,68 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// UserInfo requests the claims of the user from the userinfo endpoint with the access token.
+// The values are kept as raw JSON, so numbers like updated_at are not converted.
+func UserInfo(ctx context.Context, client *http.Client, endpoint, accessToken string) (map[string]json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	claims := make(map[string]json.RawMessage)
+	if _, err = do(client, req, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Revoke revokes the access or refresh token (RFC 7009), authenticated by auth.
+// Without auth, the client ID is sent as public client. The tokenTypeHint is optional.
+func Revoke(ctx context.Context, client *http.Client, endpoint, clientID, token, tokenTypeHint string, auth ClientAuth) error {
+	form := url.Values{"token": {token}}
+	setIfNotEmpty(form, "token_type_hint", tokenTypeHint)
+	if auth == nil {
+		form.Set("client_id", clientID)
+	}
+	_, err := PostForm(ctx, client, endpoint, form, auth, nil)
+	return err
+}
+
+// EndSessionRequest are the parameters of an RP-initiated logout, which are all optional.
+type EndSessionRequest struct {
+	IDTokenHint           string
+	ClientID              string
+	PostLogoutRedirectURI string
+	State                 string
+	LogoutHint            string
+	UILocales             string
+}
+
+// EndSessionURL returns the URL of the end session endpoint with the logout request parameters.
+// A post logout redirect URI requires the ID token hint or the client ID to identify the client.
+func EndSessionURL(endpoint string, req *EndSessionRequest) (string, error) {
+	if req.PostLogoutRedirectURI != "" && req.IDTokenHint == "" && req.ClientID == "" {
+		return "", errors.New("post logout redirect URI requires an ID token hint or a client ID")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("end session endpoint: %w", err)
+	}
+	query := u.Query()
+	setIfNotEmpty(query, "id_token_hint", req.IDTokenHint)
+	setIfNotEmpty(query, "client_id", req.ClientID)
+	setIfNotEmpty(query, "post_logout_redirect_uri", req.PostLogoutRedirectURI)
+	setIfNotEmpty(query, "state", req.State)
+	setIfNotEmpty(query, "logout_hint", req.LogoutHint)
+	setIfNotEmpty(query, "ui_locales", req.UILocales)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
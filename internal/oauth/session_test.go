@@ -0,0 +1,68 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"sub":"user","updated_at":1760000000}`))
+	}))
+	defer server.Close()
+
+	got, err := UserInfo(context.Background(), nil, server.URL, "token")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"sub": json.RawMessage(`"user"`), "updated_at": json.RawMessage(`1760000000`)}, got)
+
+	_, err = UserInfo(context.Background(), nil, server.URL, "expired")
+	assert.EqualError(t, err, "invalid_token (status 401)")
+}
+
+func TestEndSessionURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *EndSessionRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			req:  &EndSessionRequest{},
+			want: "https://zitadel.example.com/oidc/v1/end_session",
+		},
+		{
+			name:    "redirect without client",
+			req:     &EndSessionRequest{PostLogoutRedirectURI: "http://localhost:8080/logout"},
+			wantErr: true,
+		},
+		{
+			name: "redirect",
+			req:  &EndSessionRequest{ClientID: "client", PostLogoutRedirectURI: "http://localhost:8080/logout", State: "state"},
+			want: "https://zitadel.example.com/oidc/v1/end_session?client_id=client&post_logout_redirect_uri=http%3A%2F%2Flocalhost%3A8080%2Flogout&state=state",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EndSessionURL("https://zitadel.example.com/oidc/v1/end_session", tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
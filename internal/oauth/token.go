@@ -0,0 +1,91 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+// TokenResponse is a successful response of the token endpoint.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	// IssuedTokenType is the type of the access token issued by a token exchange.
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+
+	// Raw is the unmodified response body including all additional fields.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Token sends the token request form to the token endpoint, authenticated by auth if not nil.
+func Token(ctx context.Context, client *http.Client, endpoint string, form url.Values, auth ClientAuth) (*TokenResponse, error) {
+	token := new(TokenResponse)
+	raw, err := PostForm(ctx, client, endpoint, form, auth, token)
+	if err != nil {
+		return nil, err
+	}
+	token.Raw = raw
+	return token, nil
+}
+
+// JWTProfile exchanges the signed assertion for an access token
+// with the JWT bearer grant (RFC 7523).
+func JWTProfile(ctx context.Context, client *http.Client, endpoint, assertion string, scopes []string) (*TokenResponse, error) {
+	form := url.Values{
+		"grant_type": {string(oidc.GrantTypeBearer)},
+		"assertion":  {assertion},
+	}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	return Token(ctx, client, endpoint, form, nil)
+}
+
+// ClientCredentials requests an access token for the client authenticated by auth
+// with the client credentials grant.
+func ClientCredentials(ctx context.Context, client *http.Client, endpoint string, auth ClientAuth, scopes []string) (*TokenResponse, error) {
+	form := url.Values{
+		"grant_type": {string(oidc.GrantTypeClientCredentials)},
+	}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	return Token(ctx, client, endpoint, form, auth)
+}
+
+// AuthorizationCode exchanges the code received at redirectURI for tokens with the authorization code grant,
+// proving the possession of the PKCE code verifier. Without auth, the client ID is sent as public client.
+func AuthorizationCode(ctx context.Context, client *http.Client, endpoint, clientID, code, redirectURI, codeVerifier string, auth ClientAuth) (*TokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {string(oidc.GrantTypeCode)},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	}
+	if auth == nil {
+		form.Set("client_id", clientID)
+	}
+	return Token(ctx, client, endpoint, form, auth)
+}
+
+// IndentedJSON returns the raw token response as indented JSON.
+func (t *TokenResponse) IndentedJSON() ([]byte, error) {
+	return indentJSON(t.Raw)
+}
+
+func indentJSON(raw json.RawMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(raw), "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "token")
+
+	require.NoError(t, WriteFile(name, []byte("first"), 0600))
+	require.NoError(t, WriteFile(name, []byte("second"), 0600))
+
+	got, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(got))
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files must be removed")
+
+	assert.Error(t, WriteFile(filepath.Join(dir, "missing", "token"), []byte("foo"), 0600))
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// FormatRaw prints the token only.
+	FormatRaw = "raw"
+	// FormatJSON prints a JSON object with the token, exp, kid and aud.
+	FormatJSON = "json"
+	// FormatHeader prints an Authorization header with the token as bearer token.
+	FormatHeader = "header"
+	// FormatEnv prints shell export lines.
+	FormatEnv = "env"
+	// FormatCurl prints a curl command sending the token to $URL.
+	FormatCurl = "curl"
+	// FormatHTTPie prints an HTTPie command sending the token to $URL.
+	FormatHTTPie = "httpie"
+
+	// DefaultEnvVar is the variable name used by [FormatEnv].
+	DefaultEnvVar = "ZITADEL_TOKEN"
+)
+
+// Formats are all supported output formats.
+var Formats = []string{FormatRaw, FormatJSON, FormatHeader, FormatEnv, FormatCurl, FormatHTTPie}
+
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Flags are the command line flags selecting the output format of a token.
+type Flags struct {
+	Format string
+	EnvVar string
+}
+
+// Register adds the output flags to the flag set.
+func (f *Flags) Register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Format, "format", FormatRaw, "output format: "+strings.Join(Formats, ", "))
+	flags.StringVar(&f.EnvVar, "env-var", DefaultEnvVar, "name of the variable exported by the env format")
+}
+
+// Validate checks the format and variable name, so commands can fail before requesting a token.
+func (f *Flags) Validate() error {
+	_, err := f.Render(&Token{})
+	return err
+}
+
+// Render returns the token in the selected format, followed by a newline.
+func (f *Flags) Render(token *Token) ([]byte, error) {
+	switch f.Format {
+	case FormatRaw, "":
+		return []byte(token.Value + "\n"), nil
+	case FormatJSON:
+		return renderJSON(token)
+	case FormatHeader:
+		return []byte("Authorization: Bearer " + token.Value + "\n"), nil
+	case FormatEnv:
+		return f.renderEnv(token)
+	case FormatCurl:
+		return []byte(fmt.Sprintf("curl -H %s \"$URL\"\n", shellQuote("Authorization: Bearer "+token.Value))), nil
+	case FormatHTTPie:
+		return []byte(fmt.Sprintf("http \"$URL\" %s\n", shellQuote("Authorization:Bearer "+token.Value))), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q, must be one of %s", f.Format, strings.Join(Formats, ", "))
+	}
+}
+
+func renderJSON(token *Token) ([]byte, error) {
+	doc := struct {
+		Token    string   `json:"token"`
+		Expiry   int64    `json:"exp,omitempty"`
+		KeyID    string   `json:"kid,omitempty"`
+		Audience []string `json:"aud,omitempty"`
+	}{
+		Token:    token.Value,
+		KeyID:    token.KeyID,
+		Audience: token.Audience,
+	}
+	if !token.Expiry.IsZero() {
+		doc.Expiry = token.Expiry.Unix()
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (f *Flags) renderEnv(token *Token) ([]byte, error) {
+	name := f.EnvVar
+	if name == "" {
+		name = DefaultEnvVar
+	}
+	if !envVarName.MatchString(name) {
+		return nil, fmt.Errorf("invalid variable name %q", name)
+	}
+	lines := fmt.Sprintf("export %s=%s\n", name, shellQuote(token.Value))
+	if !token.Expiry.IsZero() {
+		lines += fmt.Sprintf("export %s_EXP=%d\n", name, token.Expiry.Unix())
+	}
+	return []byte(lines), nil
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
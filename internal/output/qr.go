@@ -0,0 +1,41 @@
+package output
+
+import (
+	"io"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// qrQuietZone is the number of light modules around the QR code required by scanners (ISO/IEC 18004).
+const qrQuietZone = 4
+
+// WriteQRCode writes text as QR code to a terminal, using half blocks to draw two rows of modules per line.
+// The colors are set explicitly, so the code is scannable on dark and light terminals.
+func WriteQRCode(w io.Writer, text string) error {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for y := -qrQuietZone; y < code.Size+qrQuietZone; y += 2 {
+		b.WriteString("\x1b[97;40m")
+		for x := -qrQuietZone; x < code.Size+qrQuietZone; x++ {
+			// the light modules are drawn, Black reports the modules outside of the code as light
+			top, bottom := !code.Black(x, y), !code.Black(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"rsc.io/qr"
+)
+
+func TestWriteQRCode(t *testing.T) {
+	text := "https://zitadel.cloud/device?user_code=ABCD-EFGH"
+	var buf bytes.Buffer
+	require.NoError(t, WriteQRCode(&buf, text))
+
+	code, err := qr.Encode(text, qr.L)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, (code.Size+2*qrQuietZone+1)/2)
+	for i, line := range lines {
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "\x1b[97;40m"), "\x1b[0m")
+		cells := []rune(line)
+		require.Len(t, cells, code.Size+2*qrQuietZone)
+		y := 2*i - qrQuietZone
+		for j, cell := range cells {
+			x := j - qrQuietZone
+			top, bottom := cell == '█' || cell == '▀', cell == '█' || cell == '▄'
+			assert.Equal(t, !code.Black(x, y), top, "module %d,%d", x, y)
+			assert.Equal(t, !code.Black(x, y+1), bottom, "module %d,%d", x, y+1)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/zitadel-tools/internal/ref"
+)
+
+// The formats of the commands printing results instead of a token, besides [FormatJSON].
+const (
+	// FormatTable prints the results aligned in columns.
+	FormatTable = "table"
+	// FormatText prints the results as plain lines.
+	FormatText = "text"
+)
+
+// CheckFormat returns an error if format is none of the supported formats.
+func CheckFormat(format string, supported ...string) error {
+	if slices.Contains(supported, format) {
+		return nil
+	}
+	last := len(supported) - 1
+	return fmt.Errorf("unsupported format %q, must be %s or %s", format, strings.Join(supported[:last], ", "), supported[last])
+}
+
+// OrDash returns s, or "-" if it is empty, so empty cells of a table stay visible.
+func OrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// TokenRef returns the reference of the token read by [ReadToken] for [ref.CheckStdin]:
+// "-" if it is read from stdin. name describes the token in the errors, e.g. "jwt".
+func TokenRef(args []string, name string) ref.Flag {
+	if len(args) == 1 && args[0] != "-" {
+		return ref.Flag{Name: "the " + name, Ref: args[0]}
+	}
+	return ref.Flag{Name: "the " + name, Ref: "-"}
+}
+
+// ReadToken returns the token passed as the only argument, or read from in if there is none or it is "-".
+// name describes the token in the errors, e.g. "jwt".
+func ReadToken(in io.Reader, args []string, name string) (string, error) {
+	if len(args) == 1 && args[0] != "-" {
+		return strings.TrimSpace(args[0]), nil
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", name, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.New("please provide a " + name)
+	}
+	return token, nil
+}
@@ -0,0 +1,41 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFormat(t *testing.T) {
+	assert.NoError(t, CheckFormat(FormatJSON, FormatTable, FormatJSON))
+	assert.EqualError(t, CheckFormat("yaml", FormatTable, FormatJSON), `unsupported format "yaml", must be table or json`)
+	assert.EqualError(t, CheckFormat("", FormatTable, FormatText, FormatJSON), `unsupported format "", must be table, text or json`)
+}
+
+func TestReadToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    string
+		wantErr string
+	}{
+		{name: "argument", args: []string{" token\n"}, stdin: "other", want: "token"},
+		{name: "stdin", stdin: "token\n", want: "token"},
+		{name: "dash", args: []string{"-"}, stdin: "token\n", want: "token"},
+		{name: "empty stdin", stdin: "\n", wantErr: "please provide a jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadToken(strings.NewReader(tt.stdin), tt.args, "jwt")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
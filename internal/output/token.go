@@ -0,0 +1,51 @@
+package output
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+// tokenAlgorithms are the algorithms of the JWTs whose header and claims are read by [NewToken].
+var tokenAlgorithms = append([]jose.SignatureAlgorithm{jose.HS256, jose.HS384, jose.HS512}, key.SignatureAlgorithms...)
+
+// Token is a token with the details shown by the JSON format.
+type Token struct {
+	Value    string
+	Expiry   time.Time
+	KeyID    string
+	Audience []string
+}
+
+// NewToken returns the token with the expiry, kid and audience read from its JWT header and claims.
+// The signature is not verified. For opaque tokens, the expiry is computed from expiresIn if it is positive.
+func NewToken(value string, expiresIn time.Duration) *Token {
+	token := &Token{Value: value}
+	if sig, err := jose.ParseSigned(value, tokenAlgorithms); err == nil {
+		token.KeyID = sig.Signatures[0].Header.KeyID
+		claims := new(struct {
+			Audience   oidc.Audience `json:"aud"`
+			Expiration oidc.Time     `json:"exp"`
+		})
+		if json.Unmarshal(sig.UnsafePayloadWithoutVerification(), claims) == nil {
+			token.Audience = claims.Audience
+			if claims.Expiration != 0 {
+				token.Expiry = claims.Expiration.AsTime()
+			}
+		}
+	}
+	if token.Expiry.IsZero() && expiresIn > 0 {
+		token.Expiry = time.Now().Add(expiresIn).Truncate(time.Second)
+	}
+	return token
+}
+
+// FromResponse returns the access token of the token response as [NewToken].
+func FromResponse(resp *oauth.TokenResponse) *Token {
+	return NewToken(resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+func TestNewToken(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := key.NewSigner(privateKey, "kid", jose.RS256)
+	require.NoError(t, err)
+	jwt, err := assertion.Sign(signer, "issuer", "subject", &assertion.Options{
+		Audience: []string{"https://example.com"},
+		Lifetime: time.Hour,
+	})
+	require.NoError(t, err)
+
+	got := NewToken(jwt, time.Minute)
+	assert.Equal(t, jwt, got.Value)
+	assert.Equal(t, "kid", got.KeyID)
+	assert.Equal(t, []string{"https://example.com"}, got.Audience)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), got.Expiry, 2*time.Second, "exp claim takes precedence over expires_in")
+
+	opaque := NewToken("opaque", time.Minute)
+	assert.Empty(t, opaque.KeyID)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), opaque.Expiry, 2*time.Second)
+
+	assert.True(t, NewToken("opaque", 0).Expiry.IsZero())
+}
@@ -0,0 +1,83 @@
+// Package ref reads the content referenced on the command line by stdin, an environment variable,
+// a file descriptor or a path, so keys and secrets don't have to be passed as plain values.
+package ref
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Read returns the content referenced by ref, which is one of
+//   - "-" to read from stdin
+//   - "env:VAR" to read from the environment variable VAR
+//   - "fd:N" to read from the open file descriptor N (e.g. from process substitution),
+//     which Read takes ownership of and closes after reading, so it can only be read once
+//   - a path to a file, regardless of its extension
+func Read(ref string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case ref == "":
+		return nil, errors.New("empty reference")
+	case ref == "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+		return data, nil
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return []byte(value), nil
+	case strings.HasPrefix(ref, "fd:"):
+		fd, err := strconv.ParseUint(strings.TrimPrefix(ref, "fd:"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file descriptor %q: %w", ref, err)
+		}
+		f := os.NewFile(uintptr(fd), ref)
+		if f == nil {
+			return nil, fmt.Errorf("invalid file descriptor %q", ref)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", ref, err)
+		}
+		return data, nil
+	default:
+		return os.ReadFile(ref)
+	}
+}
+
+// Flag is the reference passed to a command line flag or argument, which is named in the errors of [CheckStdin].
+type Flag struct {
+	Name string
+	Ref  string
+}
+
+// CheckStdin returns an error if more than one of the flags reads from stdin,
+// as the first one would consume it and leave nothing for the others.
+// Commands call it before reading any of the references.
+func CheckStdin(flags ...Flag) error {
+	var count int
+	var names []string
+	for _, flag := range flags {
+		if flag.Ref != "-" {
+			continue
+		}
+		count++
+		if !slices.Contains(names, flag.Name) {
+			names = append(names, flag.Name)
+		}
+	}
+	if count < 2 {
+		return nil
+	}
+	return fmt.Errorf("stdin can only be read once, but - is passed %d times (%s), pass the others as env:VAR, fd:N or a file path", count, strings.Join(names, ", "))
+}
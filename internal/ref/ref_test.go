@@ -0,0 +1,88 @@
+package ref
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ref")
+	require.NoError(t, os.WriteFile(path, []byte("from file"), 0600))
+	t.Setenv("ZITADEL_TEST_REF", "from env")
+	// a raw descriptor without an *os.File, which would close it again, as Read takes ownership of it
+	fd, err := syscall.Open(path, syscall.O_RDONLY, 0)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", ref: "", wantErr: true},
+		{name: "stdin", ref: "-", want: "from stdin"},
+		{name: "env", ref: "env:ZITADEL_TEST_REF", want: "from env"},
+		{name: "missing env", ref: "env:ZITADEL_TEST_MISSING", wantErr: true},
+		{name: "fd", ref: fmt.Sprintf("fd:%d", fd), want: "from file"},
+		{name: "fd closed after reading", ref: fmt.Sprintf("fd:%d", fd), wantErr: true},
+		{name: "invalid fd", ref: "fd:foo", wantErr: true},
+		{name: "file without extension", ref: path, want: "from file"},
+		{name: "missing file", ref: filepath.Join(dir, "foo"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(tt.ref, strings.NewReader("from stdin"))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestCheckStdin(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []Flag
+		wantErr string
+	}{
+		{
+			name:  "none",
+			flags: []Flag{{"--key", "key.json"}, {"--secret-from", "env:SECRET"}},
+		},
+		{
+			name:  "once",
+			flags: []Flag{{"--key", "-"}, {"--secret-from", "env:SECRET"}},
+		},
+		{
+			name:    "different flags",
+			flags:   []Flag{{"--key", "-"}, {"--secret-from", "-"}},
+			wantErr: "stdin can only be read once, but - is passed 2 times (--key, --secret-from), pass the others as env:VAR, fd:N or a file path",
+		},
+		{
+			name:    "repeated flag",
+			flags:   []Flag{{"--key", "-"}, {"--key", "key.json"}, {"--key", "-"}},
+			wantErr: "stdin can only be read once, but - is passed 2 times (--key), pass the others as env:VAR, fd:N or a file path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckStdin(tt.flags...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
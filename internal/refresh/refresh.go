@@ -0,0 +1,128 @@
+// Package refresh keeps tokens fresh by requesting new ones before they expire.
+package refresh
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+const (
+	// DefaultExpiresIn is assumed for tokens returned without expires_in.
+	DefaultExpiresIn = 5 * time.Minute
+	// DefaultMinInterval is the shortest time between two refreshes of a valid token.
+	DefaultMinInterval = 10 * time.Second
+	// DefaultMinBackoff is the wait time after the first failed refresh.
+	DefaultMinBackoff = time.Second
+	// DefaultMaxBackoff is the longest wait time after repeated failures.
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Loop requests a token with Fetch and passes it to Handle,
+// then requests the next token before the current one expires.
+type Loop struct {
+	// Fetch requests a new token.
+	Fetch func(ctx context.Context) (*oauth.TokenResponse, error)
+	// Handle is called with every new token, e.g. to store it.
+	// A returned error is handled like a failed Fetch.
+	Handle func(token *oauth.TokenResponse) error
+	// OnError is called with every error of Fetch or Handle, if not nil.
+	OnError func(err error, retryIn time.Duration)
+
+	MinInterval time.Duration
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// NewLoop returns a loop with the default intervals.
+func NewLoop(fetch func(ctx context.Context) (*oauth.TokenResponse, error), handle func(token *oauth.TokenResponse) error) *Loop {
+	return &Loop{
+		Fetch:       fetch,
+		Handle:      handle,
+		MinInterval: DefaultMinInterval,
+		MinBackoff:  DefaultMinBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+	}
+}
+
+// Run refreshes the token until ctx is done and returns nil then.
+func (l *Loop) Run(ctx context.Context) error {
+	return l.run(ctx, 0)
+}
+
+// RunAfter is like [Loop.Run], but for a token which was just fetched:
+// the first refresh happens before that token expires.
+func (l *Loop) RunAfter(ctx context.Context, token *oauth.TokenResponse) error {
+	return l.run(ctx, l.refreshIn(token))
+}
+
+func (l *Loop) run(ctx context.Context, wait time.Duration) error {
+	failures := 0
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		var err error
+		wait, err = l.refresh(ctx)
+		if err != nil {
+			wait = Backoff(failures, l.MinBackoff, l.MaxBackoff)
+			failures++
+			if l.OnError != nil {
+				l.OnError(err, wait)
+			}
+		} else {
+			failures = 0
+		}
+	}
+}
+
+func (l *Loop) refresh(ctx context.Context) (time.Duration, error) {
+	token, err := l.Fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err = l.Handle(token); err != nil {
+		return 0, err
+	}
+	return l.refreshIn(token), nil
+}
+
+func (l *Loop) refreshIn(token *oauth.TokenResponse) time.Duration {
+	return max(NextRefresh(ExpiresIn(token)), l.MinInterval)
+}
+
+// ExpiresIn returns the lifetime of the token or [DefaultExpiresIn] if the server did not return it.
+func ExpiresIn(token *oauth.TokenResponse) time.Duration {
+	if token.ExpiresIn <= 0 {
+		return DefaultExpiresIn
+	}
+	return time.Duration(token.ExpiresIn) * time.Second
+}
+
+// NextRefresh returns the time after which a token expiring in expiresIn should be refreshed.
+// It is randomly picked between 60% and 80% of the lifetime,
+// so multiple instances don't refresh at the same moment.
+func NextRefresh(expiresIn time.Duration) time.Duration {
+	return time.Duration(float64(expiresIn) * (0.6 + 0.2*rand.Float64()))
+}
+
+// Backoff returns the wait time after the given number of previous failures:
+// an exponentially growing duration from minimum to maximum, of which a random half is used.
+func Backoff(failures int, minimum, maximum time.Duration) time.Duration {
+	backoff := minimum
+	for range failures {
+		backoff *= 2
+		if backoff >= maximum {
+			backoff = maximum
+			break
+		}
+	}
+	return backoff/2 + rand.N(backoff/2+1)
+}
@@ -0,0 +1,80 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+func TestLoop_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var fetches, errs int
+	var handled []string
+	loop := &Loop{
+		Fetch: func(context.Context) (*oauth.TokenResponse, error) {
+			fetches++
+			if fetches == 1 {
+				return nil, errors.New("unavailable")
+			}
+			return &oauth.TokenResponse{AccessToken: "token", ExpiresIn: 0}, nil
+		},
+		Handle: func(token *oauth.TokenResponse) error {
+			handled = append(handled, token.AccessToken)
+			return nil
+		},
+		OnError: func(err error, retryIn time.Duration) {
+			errs++
+			assert.LessOrEqual(t, retryIn, time.Millisecond)
+		},
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Second,
+	}
+	done := make(chan error)
+	go func() { done <- loop.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("loop stopped early: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, 1, errs)
+	assert.Equal(t, []string{"token"}, handled, "token without expires_in must be refreshed after the default lifetime")
+}
+
+func TestNextRefresh(t *testing.T) {
+	for range 100 {
+		got := NextRefresh(time.Hour)
+		assert.GreaterOrEqual(t, got, 36*time.Minute)
+		assert.LessOrEqual(t, got, 48*time.Minute)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		wantMax  time.Duration
+	}{
+		{name: "first", failures: 0, wantMax: time.Second},
+		{name: "third", failures: 2, wantMax: 4 * time.Second},
+		{name: "capped", failures: 100, wantMax: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := Backoff(tt.failures, time.Second, time.Minute)
+				assert.GreaterOrEqual(t, got, tt.wantMax/2)
+				assert.LessOrEqual(t, got, tt.wantMax)
+			}
+		})
+	}
+}
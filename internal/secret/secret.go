@@ -0,0 +1,83 @@
+// Package secret reads secrets without exposing them in the process list or the shell history.
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"github.com/zitadel/zitadel-tools/internal/ref"
+)
+
+// ErrMissing is returned by [Flags.Read] if no secret was provided and it can't be prompted for.
+var ErrMissing = errors.New("no secret provided")
+
+// Flags are the command line flags to provide a secret:
+// --<name>-from reads it from a reference, --<name> takes it as plain value.
+type Flags struct {
+	Value string
+	From  string
+
+	name  string
+	label string
+}
+
+// Register adds the --<name> and --<name>-from flags to the flag set.
+// The label describes the secret in the help and the prompt, e.g. "client secret".
+func (f *Flags) Register(flags *pflag.FlagSet, name, label string) {
+	f.name, f.label = name, label
+	flags.StringVar(&f.From, name+"-from", "", "read the "+label+" from - for stdin, env:VAR, fd:N or a file path")
+	flags.StringVar(&f.Value, name, "", label+" as plain value; insecure, it is visible in the process list and shell history")
+}
+
+// Provided reports whether the secret was passed by one of the flags,
+// so optional secrets are only read, and maybe prompted for, if requested.
+func (f *Flags) Provided() bool {
+	return f.From != "" || f.Value != ""
+}
+
+// Ref returns the reference of the --<name>-from flag for [ref.CheckStdin].
+func (f *Flags) Ref() ref.Flag {
+	return ref.Flag{Name: "--" + f.name + "-from", Ref: f.From}
+}
+
+// Read returns the secret from the reference of the --<name>-from flag or the value of the --<name> flag,
+// after printing a warning to log. If neither is set and stdin is a terminal, the secret is prompted for without echo.
+func (f *Flags) Read(stdin *os.File, log io.Writer) (string, error) {
+	switch {
+	case f.From != "" && f.Value != "":
+		return "", fmt.Errorf("please provide either --%s or --%s-from", f.name, f.name)
+	case f.From != "":
+		data, err := ref.Read(f.From, stdin)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", f.label, err)
+		}
+		secret := strings.TrimRight(string(data), "\r\n")
+		if secret == "" {
+			return "", fmt.Errorf("%s from %s is empty", f.label, f.From)
+		}
+		return secret, nil
+	case f.Value != "":
+		fmt.Fprintf(log, "WARN: --%s exposes the %s in the process list and shell history, prefer --%s-from or the prompt\n", f.name, f.label, f.name)
+		return f.Value, nil
+	}
+	fd := int(stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("%w: please provide the %s with --%s-from", ErrMissing, f.label, f.name)
+	}
+	fmt.Fprintf(log, "%s%s: ", strings.ToUpper(f.label[:1]), f.label[1:])
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(log)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", f.label, err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w: %s is empty", ErrMissing, f.label)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,96 @@
+package secret
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlags_Read(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from file\n"), 0600))
+	emptyFile := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(emptyFile, nil, 0600))
+	t.Setenv("TEST_CLIENT_SECRET", "from env")
+
+	tests := []struct {
+		name      string
+		args      []string
+		stdin     string
+		want      string
+		wantWarn  bool
+		wantErr   bool
+		wantErrIs error
+	}{
+		{
+			name: "file",
+			args: []string{"--secret-from=" + secretFile},
+			want: "from file",
+		},
+		{
+			name: "env",
+			args: []string{"--secret-from=env:TEST_CLIENT_SECRET"},
+			want: "from env",
+		},
+		{
+			name:  "stdin",
+			args:  []string{"--secret-from=-"},
+			stdin: "from stdin\r\n",
+			want:  "from stdin",
+		},
+		{
+			name:     "flag",
+			args:     []string{"--secret=from flag"},
+			want:     "from flag",
+			wantWarn: true,
+		},
+		{
+			name:    "empty file",
+			args:    []string{"--secret-from=" + emptyFile},
+			wantErr: true,
+		},
+		{
+			name:    "both",
+			args:    []string{"--secret=foo", "--secret-from=env:TEST_CLIENT_SECRET"},
+			wantErr: true,
+		},
+		{
+			name:      "none without terminal",
+			wantErr:   true,
+			wantErrIs: ErrMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f Flags
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			f.Register(flags, "secret", "client secret")
+			require.NoError(t, flags.Parse(tt.args))
+
+			stdinPath := filepath.Join(t.TempDir(), "stdin")
+			require.NoError(t, os.WriteFile(stdinPath, []byte(tt.stdin), 0600))
+			stdin, err := os.Open(stdinPath)
+			require.NoError(t, err)
+			defer stdin.Close()
+
+			var log bytes.Buffer
+			got, err := f.Read(stdin, &log)
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantWarn, bytes.Contains(log.Bytes(), []byte("WARN")))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package mockop
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+// clockSkew tolerates clock drift between the signer of an assertion and the OP.
+const clockSkew = time.Minute
+
+// assertionClaims are the claims of a JWT profile grant or client assertion checked by the OP.
+type assertionClaims struct {
+	Issuer     string        `json:"iss"`
+	Subject    string        `json:"sub"`
+	Audience   oidc.Audience `json:"aud"`
+	Expiration oidc.Time     `json:"exp"`
+	IssuedAt   oidc.Time     `json:"iat"`
+	NotBefore  oidc.Time     `json:"nbf"`
+}
+
+// verifyAssertion verifies the signature of the assertion with the registered key of its kid,
+// which must be of keyType, and checks that it was issued by the subject of the key for the OP.
+func (op *OP) verifyAssertion(assertion, keyType string) (*key.File, error) {
+	sig, err := jose.ParseSigned(assertion, key.SignatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("assertion: %w", err)
+	}
+	kid := sig.Signatures[0].Header.KeyID
+	registered, ok := op.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("assertion: key %q is not registered", kid)
+	}
+	if registered.file.Type != keyType {
+		return nil, fmt.Errorf("assertion: key %q is an %s key, expected %s", kid, registered.file.Type, keyType)
+	}
+	payload, err := sig.Verify(&registered.jwk)
+	if err != nil {
+		return nil, fmt.Errorf("assertion: %w", err)
+	}
+	claims := new(assertionClaims)
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("assertion: %w", err)
+	}
+	now := op.now()
+	subject := registered.file.Subject()
+	switch {
+	case claims.Issuer != subject || claims.Subject != subject:
+		return nil, fmt.Errorf("assertion: iss %q and sub %q must be %q", claims.Issuer, claims.Subject, subject)
+	case !slices.Contains(claims.Audience, op.issuer):
+		// ZITADEL compares the audience exactly, so a trailing slash is rejected as well
+		return nil, fmt.Errorf("assertion: audience %v does not contain the issuer %q", []string(claims.Audience), op.issuer)
+	case now.After(claims.Expiration.AsTime().Add(clockSkew)):
+		return nil, fmt.Errorf("assertion: expired at %s", claims.Expiration.AsTime().UTC().Format(time.RFC3339))
+	case claims.IssuedAt.AsTime().After(now.Add(clockSkew)):
+		return nil, fmt.Errorf("assertion: issued in the future at %s", claims.IssuedAt.AsTime().UTC().Format(time.RFC3339))
+	case claims.NotBefore != 0 && claims.NotBefore.AsTime().After(now.Add(clockSkew)):
+		return nil, fmt.Errorf("assertion: not valid before %s", claims.NotBefore.AsTime().UTC().Format(time.RFC3339))
+	}
+	return registered.file, nil
+}
+
+// authenticateClient returns the ID of the client authenticated by the request
+// with client_secret_basic, client_secret_post or private_key_jwt.
+func (op *OP) authenticateClient(r *http.Request) (string, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		credentials, err := oauth.ParseBasicAuth(header)
+		if err != nil {
+			return "", err
+		}
+		return op.checkSecret(credentials.ClientID, credentials.ClientSecret)
+	}
+	if clientAssertion := r.PostFormValue("client_assertion"); clientAssertion != "" {
+		if assertionType := r.PostFormValue("client_assertion_type"); assertionType != oidc.ClientAssertionTypeJWTAssertion {
+			return "", fmt.Errorf("unsupported client_assertion_type %q", assertionType)
+		}
+		file, err := op.verifyAssertion(clientAssertion, key.TypeApplication)
+		if err != nil {
+			return "", err
+		}
+		return file.ClientID, nil
+	}
+	if clientID := r.PostFormValue("client_id"); clientID != "" {
+		return op.checkSecret(clientID, r.PostFormValue("client_secret"))
+	}
+	return "", errors.New("no client authentication")
+}
+
+func (op *OP) checkSecret(clientID, clientSecret string) (string, error) {
+	secret, ok := op.config.Clients[clientID]
+	if !ok || subtle.ConstantTimeCompare([]byte(secret), []byte(clientSecret)) != 1 {
+		return "", fmt.Errorf("unknown client %q or wrong secret", clientID)
+	}
+	return clientID, nil
+}
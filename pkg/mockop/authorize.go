@@ -0,0 +1,184 @@
+package mockop
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+const (
+	// grantRequestLifetime is the lifetime of the authorization and device codes.
+	grantRequestLifetime = 5 * time.Minute
+	// deviceInterval is the polling interval of the device authorization grant in seconds.
+	deviceInterval = 1
+	// userCodeCharset are the characters of the user codes, without vowels like ZITADEL uses.
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+)
+
+// grantRequest is a pending authorization code or device authorization request.
+type grantRequest struct {
+	clientID    string
+	redirectURI string
+	challenge   *oidc.CodeChallenge
+	nonce       string
+	scopes      []string
+	expiry      time.Time
+}
+
+// handleAuthorize redirects to the redirect_uri with a code for the configured user, as there is no login to prompt for,
+// or with access_denied if no user is configured. Only the code flow with PKCE (S256) is supported.
+func (op *OP) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirect.IsAbs() || query.Get("client_id") == "" {
+		// without a valid redirect URI the error can only be shown to the user
+		http.Error(w, "client_id and an absolute redirect_uri are required", http.StatusBadRequest)
+		return
+	}
+	params := url.Values{}
+	if state := query.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	code, description := op.authorize(query)
+	if description != "" {
+		params.Set("error", code)
+		params.Set("error_description", description)
+	} else {
+		params.Set("code", code)
+	}
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// authorize returns the code of the authorization request,
+// or the error code and description if the request is invalid or denied.
+func (op *OP) authorize(query url.Values) (code, description string) {
+	scopes := strings.Fields(query.Get("scope"))
+	method := oidc.CodeChallengeMethod(query.Get("code_challenge_method"))
+	switch {
+	case oidc.ResponseType(query.Get("response_type")) != oidc.ResponseTypeCode:
+		return "unsupported_response_type", "only the code response type is supported"
+	case query.Get("code_challenge") == "" || method != oidc.CodeChallengeMethodS256:
+		return "invalid_request", "a code_challenge with the S256 method is required"
+	case op.config.User == "":
+		return "access_denied", "no user is configured to log in"
+	}
+	if err := oauth.ValidateScopes(scopes); err != nil {
+		return "invalid_scope", err.Error()
+	}
+	code, err := newTokenID()
+	if err != nil {
+		return "server_error", err.Error()
+	}
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	op.codes[code] = &grantRequest{
+		clientID:    query.Get("client_id"),
+		redirectURI: query.Get("redirect_uri"),
+		challenge:   &oidc.CodeChallenge{Challenge: query.Get("code_challenge"), Method: method},
+		nonce:       query.Get("nonce"),
+		scopes:      scopes,
+		expiry:      op.now().Add(grantRequestLifetime),
+	}
+	return code, ""
+}
+
+// handleDeviceAuthorization issues a device and user code, which are approved for the configured user
+// right away, as there is no login to prompt for.
+func (op *OP) handleDeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	clientID, err := op.requestClient(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	}
+	scopes := strings.Fields(r.PostFormValue("scope"))
+	if err = oauth.ValidateScopes(scopes); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	}
+	deviceCode, err := newTokenID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	userCode := newUserCode()
+	op.mu.Lock()
+	op.deviceCodes[deviceCode] = &grantRequest{
+		clientID: clientID,
+		scopes:   scopes,
+		expiry:   op.now().Add(grantRequestLifetime),
+	}
+	op.mu.Unlock()
+	// the device login UI of ZITADEL, which the mock does not serve
+	verificationURI := op.issuer + "/device"
+	writeJSON(w, &oidc.DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + userCode,
+		ExpiresIn:               int(grantRequestLifetime.Seconds()),
+		Interval:                deviceInterval,
+	})
+}
+
+// redeem removes and returns the pending request of the code of an authorization code or device code grant,
+// after checking it was issued to the client of the request and, for authorization codes, the PKCE code verifier.
+// A device code is denied with access_denied if no user is configured.
+func (op *OP) redeem(r *http.Request, grantType oidc.GrantType) (*grantRequest, *oauth.Error) {
+	clientID, err := op.requestClient(r)
+	if err != nil {
+		return nil, &oauth.Error{StatusCode: http.StatusUnauthorized, Code: "invalid_client", Description: err.Error()}
+	}
+	requests, code := op.codes, r.PostFormValue("code")
+	if grantType == oidc.GrantTypeDeviceCode {
+		requests, code = op.deviceCodes, r.PostFormValue("device_code")
+	}
+	op.mu.Lock()
+	request, ok := requests[code]
+	delete(requests, code)
+	op.mu.Unlock()
+	switch {
+	case !ok || op.now().After(request.expiry):
+		return nil, &oauth.Error{StatusCode: http.StatusBadRequest, Code: "invalid_grant", Description: "unknown or expired code"}
+	case request.clientID != clientID:
+		return nil, &oauth.Error{StatusCode: http.StatusBadRequest, Code: "invalid_grant", Description: fmt.Sprintf("code was issued to client %q", request.clientID)}
+	case grantType == oidc.GrantTypeDeviceCode && op.config.User == "":
+		return nil, &oauth.Error{StatusCode: http.StatusBadRequest, Code: "access_denied", Description: "no user is configured to log in"}
+	case grantType == oidc.GrantTypeCode && r.PostFormValue("redirect_uri") != request.redirectURI:
+		return nil, &oauth.Error{StatusCode: http.StatusBadRequest, Code: "invalid_grant", Description: "redirect_uri does not match the authorization request"}
+	case grantType == oidc.GrantTypeCode && !oidc.VerifyCodeChallenge(request.challenge, r.PostFormValue("code_verifier")):
+		return nil, &oauth.Error{StatusCode: http.StatusBadRequest, Code: "invalid_grant", Description: "code_verifier does not match the code_challenge"}
+	}
+	return request, nil
+}
+
+// requestClient returns the client of an authorization code, device authorization or revocation request:
+// the authenticated client if credentials are sent, otherwise the client_id of a public client.
+func (op *OP) requestClient(r *http.Request) (string, error) {
+	if r.Header.Get("Authorization") != "" || r.PostFormValue("client_secret") != "" || r.PostFormValue("client_assertion") != "" {
+		return op.authenticateClient(r)
+	}
+	if clientID := r.PostFormValue("client_id"); clientID != "" {
+		return clientID, nil
+	}
+	return "", errors.New("no client_id")
+}
+
+// newUserCode returns a user code in the format XXXX-XXXX.
+func newUserCode() string {
+	code := make([]byte, 9)
+	rand.Read(code)
+	for i := range code {
+		code[i] = userCodeCharset[int(code[i])%len(userCodeCharset)]
+	}
+	code[4] = '-'
+	return string(code)
+}
@@ -0,0 +1,95 @@
+package mockop
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+)
+
+// exchangeTokenTypes are the requested token types of a token exchange.
+// Access tokens are JWTs, so access_token and jwt both request one.
+var exchangeTokenTypes = []oidc.TokenType{oidc.AccessTokenType, oidc.JWTTokenType, oidc.IDTokenType}
+
+// handleTokenExchange exchanges the subject token for a new token of its subject (RFC 8693),
+// which is issued on behalf of the subject of the actor token, if one is sent (impersonation).
+// Every authenticated client may exchange and impersonate, as the mock has no permissions to check.
+// The scopes of the subject token are used if none are requested.
+func (op *OP) handleTokenExchange(w http.ResponseWriter, r *http.Request, scopes []string) {
+	clientID, err := op.authenticateClient(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	}
+	requested := oidc.TokenType(r.PostFormValue("requested_token_type"))
+	if requested == "" {
+		requested = oidc.AccessTokenType
+	}
+	if !slices.Contains(exchangeTokenTypes, requested) {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("requested_token_type %q is not supported", requested))
+		return
+	}
+	subject, subjectScopes, err := op.verifyExchangeToken(r.PostFormValue("subject_token"), r.PostFormValue("subject_token_type"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "subject_token: "+err.Error())
+		return
+	}
+	var actor string
+	if actorToken := r.PostFormValue("actor_token"); actorToken != "" {
+		if actor, _, err = op.verifyExchangeToken(actorToken, r.PostFormValue("actor_token_type")); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "actor_token: "+err.Error())
+			return
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = subjectScopes
+	}
+	if requested == oidc.IDTokenType && !slices.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append(slices.Clone(scopes), oidc.ScopeOpenID)
+	}
+	token, err := op.issue(subject, clientID, scopes, grantClaims{actor: actor, audience: r.PostForm["audience"]})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	token.IssuedTokenType = requested
+	if requested == oidc.IDTokenType {
+		// the ID token is returned as access_token, which is not usable as bearer token
+		token.AccessToken, token.IDToken, token.TokenType = token.IDToken, "", "N_A"
+	}
+	writeJSON(w, token)
+}
+
+// verifyExchangeToken verifies a subject or actor token of tokenType and returns its subject and scopes:
+// access and ID tokens must be issued by the OP, a jwt must be signed by a registered service account key.
+func (op *OP) verifyExchangeToken(token, tokenType string) (subject string, scopes []string, err error) {
+	if token == "" {
+		return "", nil, errors.New("missing")
+	}
+	var claims map[string]any
+	switch oidc.TokenType(tokenType) {
+	case oidc.AccessTokenType:
+		claims, err = op.verifyAccessToken(token)
+	case oidc.IDTokenType:
+		claims, err = op.verifyToken(token, "JWT")
+	case oidc.JWTTokenType:
+		file, err := op.verifyAssertion(token, key.TypeServiceAccount)
+		if err != nil {
+			return "", nil, err
+		}
+		return file.UserID, nil, nil
+	default:
+		return "", nil, fmt.Errorf("token type %q is not supported", tokenType)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	subject, _ = claims["sub"].(string)
+	scope, _ := claims["scope"].(string)
+	return subject, strings.Fields(scope), nil
+}
@@ -0,0 +1,243 @@
+// Package mockop implements an OpenID Provider mimicking the endpoints of ZITADEL,
+// so clients and APIs can be tested offline, e.g. with the output of key2jwt.
+//
+// The OP serves discovery, the JSON Web Key Set, and the authorization, device authorization,
+// token, introspection, revocation, userinfo and end session endpoints.
+// Tokens are requested with the JWT profile grant of registered service account keys,
+// the client credentials grant of clients authenticated with basic auth or an application key,
+// the authorization code and device authorization grants, which log in the configured user without prompting,
+// or the token exchange grant.
+package mockop
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+// DefaultTokenLifetime is the lifetime of the issued tokens if none is configured.
+const DefaultTokenLifetime = time.Hour
+
+// The endpoints are served on the same paths as ZITADEL does.
+const (
+	AuthorizePath           = "/oauth/v2/authorize"
+	DeviceAuthorizationPath = "/oauth/v2/device_authorization"
+	TokenPath               = "/oauth/v2/token"
+	IntrospectPath          = "/oauth/v2/introspect"
+	RevokePath              = "/oauth/v2/revoke"
+	KeysPath                = "/oauth/v2/keys"
+	UserinfoPath            = "/oidc/v1/userinfo"
+	EndSessionPath          = "/oidc/v1/end_session"
+)
+
+// Config configures the clients, users and claims of an [OP].
+type Config struct {
+	// Keys are key files (key.json) of service accounts and applications.
+	// Their public keys verify the assertions of the JWT profile grant and the private_key_jwt client authentication.
+	Keys [][]byte
+	// Clients maps the IDs of clients authenticating with client_secret_basic or client_secret_post to their secrets.
+	Clients map[string]string
+	// Roles are granted to every subject and map the role keys to the organizations (ID to primary domain).
+	// They are added to the tokens and the userinfo if requested by the reserved role scopes.
+	Roles map[string]map[string]string
+	// ProjectID is added to the audience of every token and names the project specific roles claim.
+	ProjectID string
+	// Claims are added to the ID tokens and userinfo responses of the subject (user or client ID) they are mapped to.
+	Claims map[string]map[string]any
+	// User is the subject logged in by the authorization code and device authorization grants,
+	// which are approved without prompting. If empty, they are denied with access_denied.
+	User string
+	// TokenLifetime is the lifetime of the issued tokens, [DefaultTokenLifetime] if zero.
+	TokenLifetime time.Duration
+	// SigningKey signs the issued tokens. If nil, a new RSA key is generated.
+	SigningKey crypto.Signer
+	// Now returns the time of the OP, which is also sent as Date header, e.g. to test clock skew. If nil, [time.Now] is used.
+	Now func() time.Time
+}
+
+// OP is a mocked OpenID Provider, which serves its endpoints as [http.Handler].
+type OP struct {
+	issuer string
+	config Config
+	// keys are the registered key files by their key ID.
+	keys map[string]*registeredKey
+	// accessTokens and idTokens sign the tokens with the typ header of their kind.
+	accessTokens jose.Signer
+	idTokens     jose.Signer
+	jwks         jose.JSONWebKeySet
+	mux          *http.ServeMux
+	now          func() time.Time
+
+	mu sync.Mutex
+	// codes and deviceCodes are the pending authorization code and device authorization requests by their code.
+	codes       map[string]*grantRequest
+	deviceCodes map[string]*grantRequest
+	// revoked are the IDs (jti) of the revoked access tokens.
+	revoked map[string]bool
+}
+
+// registeredKey is a public key of a service account or application.
+type registeredKey struct {
+	file *key.File
+	jwk  jose.JSONWebKey
+}
+
+// New returns an OP for issuer, which must be the URL the OP is served on.
+func New(issuer string, config Config) (*OP, error) {
+	if issuer == "" {
+		return nil, errors.New("issuer must not be empty")
+	}
+	if config.TokenLifetime == 0 {
+		config.TokenLifetime = DefaultTokenLifetime
+	}
+	if config.TokenLifetime < 0 {
+		return nil, fmt.Errorf("token lifetime must be positive, got %s", config.TokenLifetime)
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	op := &OP{
+		issuer: strings.TrimSuffix(issuer, "/"),
+		config: config,
+		keys:   make(map[string]*registeredKey, len(config.Keys)),
+		mux:    http.NewServeMux(),
+		now:    config.Now,
+
+		codes:       make(map[string]*grantRequest),
+		deviceCodes: make(map[string]*grantRequest),
+		revoked:     make(map[string]bool),
+	}
+	for _, data := range config.Keys {
+		if err := op.registerKey(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := op.initSigners(); err != nil {
+		return nil, err
+	}
+	op.mux.HandleFunc("GET "+oidc.DiscoveryEndpoint, op.handleDiscovery)
+	op.mux.HandleFunc("GET "+KeysPath, op.handleKeys)
+	op.mux.HandleFunc("GET "+AuthorizePath, op.handleAuthorize)
+	op.mux.HandleFunc("POST "+DeviceAuthorizationPath, op.handleDeviceAuthorization)
+	op.mux.HandleFunc("POST "+TokenPath, op.handleToken)
+	op.mux.HandleFunc("POST "+IntrospectPath, op.handleIntrospect)
+	op.mux.HandleFunc("POST "+RevokePath, op.handleRevoke)
+	op.mux.HandleFunc("GET "+UserinfoPath, op.handleUserinfo)
+	op.mux.HandleFunc("POST "+UserinfoPath, op.handleUserinfo)
+	op.mux.HandleFunc("GET "+EndSessionPath, op.handleEndSession)
+	return op, nil
+}
+
+func (op *OP) registerKey(data []byte) error {
+	file, err := key.ParseFile(data)
+	if err != nil {
+		return err
+	}
+	if err = file.Validate(); err != nil {
+		return fmt.Errorf("key %s: %w", file.KeyID, err)
+	}
+	if _, ok := op.keys[file.KeyID]; ok {
+		return fmt.Errorf("key %s is already registered", file.KeyID)
+	}
+	public, err := key.PublicKeys(data)
+	if err != nil {
+		return fmt.Errorf("key %s: %w", file.KeyID, err)
+	}
+	op.keys[file.KeyID] = &registeredKey{file: file, jwk: public[0]}
+	return nil
+}
+
+func (op *OP) initSigners() (err error) {
+	signingKey := op.config.SigningKey
+	if signingKey == nil {
+		if signingKey, err = key.Generate(key.AlgorithmRSA, 2048, ""); err != nil {
+			return fmt.Errorf("generate signing key: %w", err)
+		}
+	}
+	alg, err := key.Algorithm(signingKey)
+	if err != nil {
+		return fmt.Errorf("signing key: %w", err)
+	}
+	jwk := jose.JSONWebKey{Key: signingKey, Algorithm: string(alg), Use: "sig"}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("signing key: %w", err)
+	}
+	// the kid is derived from the key, so it stays the same if the key is passed again after a restart
+	jwk.KeyID = fmt.Sprintf("%x", thumbprint[:8])
+	signingJWK := jose.SigningKey{Algorithm: alg, Key: &jwk}
+	if op.accessTokens, err = jose.NewSigner(signingJWK, (&jose.SignerOptions{}).WithType("at+jwt")); err != nil {
+		return err
+	}
+	if op.idTokens, err = jose.NewSigner(signingJWK, (&jose.SignerOptions{}).WithType("JWT")); err != nil {
+		return err
+	}
+	op.jwks.Keys = []jose.JSONWebKey{jwk.Public()}
+	return nil
+}
+
+// Issuer returns the issuer of the OP, which is the audience of the assertions it accepts.
+func (op *OP) Issuer() string {
+	return op.issuer
+}
+
+// Keys returns the public keys the issued tokens are signed with.
+func (op *OP) Keys() *jose.JSONWebKeySet {
+	return &jose.JSONWebKeySet{Keys: op.jwks.Keys}
+}
+
+func (op *OP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Date", op.now().UTC().Format(http.TimeFormat))
+	op.mux.ServeHTTP(w, r)
+}
+
+func (op *OP) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	authMethods := []oidc.AuthMethod{oidc.AuthMethodBasic, oidc.AuthMethodPost, oidc.AuthMethodPrivateKeyJWT}
+	writeJSON(w, &oidc.DiscoveryConfiguration{
+		Issuer:                                    op.issuer,
+		AuthorizationEndpoint:                     op.issuer + AuthorizePath,
+		DeviceAuthorizationEndpoint:               op.issuer + DeviceAuthorizationPath,
+		TokenEndpoint:                             op.issuer + TokenPath,
+		IntrospectionEndpoint:                     op.issuer + IntrospectPath,
+		RevocationEndpoint:                        op.issuer + RevokePath,
+		UserinfoEndpoint:                          op.issuer + UserinfoPath,
+		EndSessionEndpoint:                        op.issuer + EndSessionPath,
+		JwksURI:                                   op.issuer + KeysPath,
+		ScopesSupported:                           []string{oidc.ScopeOpenID, oidc.ScopeProfile, oidc.ScopeEmail, oauth.ScopeAllProjectRoles},
+		ResponseTypesSupported:                    []string{string(oidc.ResponseTypeCode)},
+		GrantTypesSupported:                       []oidc.GrantType{oidc.GrantTypeCode, oidc.GrantTypeDeviceCode, oidc.GrantTypeBearer, oidc.GrantTypeClientCredentials, oidc.GrantTypeTokenExchange},
+		SubjectTypesSupported:                     []string{"public"},
+		IDTokenSigningAlgValuesSupported:          []string{op.jwks.Keys[0].Algorithm},
+		CodeChallengeMethodsSupported:             []oidc.CodeChallengeMethod{oidc.CodeChallengeMethodS256},
+		TokenEndpointAuthMethodsSupported:         authMethods,
+		IntrospectionEndpointAuthMethodsSupported: authMethods,
+		RevocationEndpointAuthMethodsSupported:    append(authMethods, oidc.AuthMethodNone),
+	})
+}
+
+func (op *OP) handleKeys(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, &op.jwks)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&oauth.Error{Code: code, Description: description})
+}
@@ -0,0 +1,443 @@
+package mockop_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/assertion"
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/keytest"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+	"github.com/zitadel/zitadel-tools/pkg/mockop/mockoptest"
+)
+
+func TestOP(t *testing.T) {
+	serviceAccountKey := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "sa-key", UserID: "user"})
+	applicationKey := keytest.File(t, key.File{Type: key.TypeApplication, KeyID: "app-key", ClientID: "api", AppID: "app"})
+	unregisteredKey := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "other-key", UserID: "user"})
+	op := mockoptest.Start(t, mockop.Config{
+		Keys:      [][]byte{serviceAccountKey, applicationKey},
+		Clients:   map[string]string{"client": "secret"},
+		Roles:     map[string]map[string]string{"admin": {"1": "acme.example.com"}, "viewer": {"1": "acme.example.com", "2": "other.example.com"}},
+		ProjectID: "project",
+		Claims:    map[string]map[string]any{"user": {"email": "user@acme.example.com"}},
+	})
+	client := http.DefaultClient
+	ctx := context.Background()
+
+	config, err := oauth.Discover(ctx, client, op.Issuer())
+	require.NoError(t, err)
+	keys, err := oauth.FetchKeys(ctx, client, config.JwksURI)
+	require.NoError(t, err)
+	apiAuth := func() oauth.ClientAuth {
+		clientAssertion, err := assertion.ClientAssertion(applicationKey, "", op.Issuer())
+		require.NoError(t, err)
+		return oauth.PrivateKeyJWT(clientAssertion)
+	}
+
+	grant := func(data []byte, audience string) string {
+		jwt, err := assertion.FromKey(data, "", "", &assertion.Options{Audience: []string{audience}, Lifetime: time.Hour})
+		require.NoError(t, err)
+		return jwt
+	}
+	tests := []struct {
+		name      string
+		request   func(scopes []string) (*oauth.TokenResponse, error)
+		scopes    []string
+		wantErr   string
+		wantSub   string
+		wantRoles oauth.Roles
+	}{
+		{
+			name: "jwt profile of unregistered key",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.JWTProfile(ctx, client, config.TokenEndpoint, grant(unregisteredKey, op.Issuer()), scopes)
+			},
+			wantErr: "invalid_grant",
+		},
+		{
+			name: "jwt profile for other audience",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.JWTProfile(ctx, client, config.TokenEndpoint, grant(serviceAccountKey, "https://zitadel.cloud"), scopes)
+			},
+			wantErr: "invalid_grant",
+		},
+		{
+			name: "jwt profile for issuer with trailing slash",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.JWTProfile(ctx, client, config.TokenEndpoint, grant(serviceAccountKey, op.Issuer()+"/"), scopes)
+			},
+			wantErr: "invalid_grant",
+		},
+		{
+			name: "jwt profile with application key",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.JWTProfile(ctx, client, config.TokenEndpoint, grant(applicationKey, op.Issuer()), scopes)
+			},
+			wantErr: "invalid_grant",
+		},
+		{
+			name: "invalid reserved scope",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.JWTProfile(ctx, client, config.TokenEndpoint, grant(serviceAccountKey, op.Issuer()), scopes)
+			},
+			scopes:  []string{"urn:zitadel:iam:org:id:"},
+			wantErr: "invalid_scope",
+		},
+		{
+			name: "jwt profile",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.JWTProfile(ctx, client, config.TokenEndpoint, grant(serviceAccountKey, op.Issuer()), scopes)
+			},
+			scopes:    []string{"openid", "urn:zitadel:iam:org:project:role:viewer", "urn:zitadel:iam:org:id:2"},
+			wantSub:   "user",
+			wantRoles: oauth.Roles{"viewer": {"2": "other.example.com"}},
+		},
+		{
+			name: "client credentials with wrong secret",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.ClientCredentials(ctx, client, config.TokenEndpoint, oauth.ClientSecretBasic("client", "wrong"), scopes)
+			},
+			wantErr: "invalid_client",
+		},
+		{
+			name: "client credentials with basic auth",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.ClientCredentials(ctx, client, config.TokenEndpoint, oauth.ClientSecretBasic("client", "secret"), scopes)
+			},
+			scopes:    []string{"openid", "urn:zitadel:iam:org:projects:roles"},
+			wantSub:   "client",
+			wantRoles: oauth.Roles{"admin": {"1": "acme.example.com"}, "viewer": {"1": "acme.example.com", "2": "other.example.com"}},
+		},
+		{
+			name: "client credentials with application key",
+			request: func(scopes []string) (*oauth.TokenResponse, error) {
+				return oauth.ClientCredentials(ctx, client, config.TokenEndpoint, apiAuth(), scopes)
+			},
+			scopes:  []string{"openid"},
+			wantSub: "api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.request(tt.scopes)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			introspection, err := oauth.Introspect(ctx, client, config.IntrospectionEndpoint, token.AccessToken, apiAuth())
+			require.NoError(t, err)
+			assert.True(t, introspection.Active)
+			assert.Equal(t, tt.wantSub, introspection.Subject)
+			assert.Contains(t, introspection.Audience, "project")
+			assert.Equal(t, tt.wantRoles, introspection.Roles)
+
+			claims, err := oauth.VerifyIDToken(token.IDToken, keys, op.Issuer(), tt.wantSub, "", time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSub, claims.Subject)
+
+			userinfo, err := oauth.UserInfo(ctx, client, config.UserinfoEndpoint, token.AccessToken)
+			require.NoError(t, err)
+			assert.JSONEq(t, `"`+tt.wantSub+`"`, string(userinfo["sub"]))
+			if tt.wantSub == "user" {
+				assert.JSONEq(t, `"user@acme.example.com"`, string(userinfo["email"]))
+			}
+		})
+	}
+
+	t.Run("introspection of other tokens", func(t *testing.T) {
+		token, err := op.Issue("user", "user", []string{"openid"})
+		require.NoError(t, err)
+		for name, value := range map[string]string{"id token": token.IDToken, "garbage": "token"} {
+			introspection, err := oauth.Introspect(ctx, client, config.IntrospectionEndpoint, value, oauth.ClientSecretBasic("client", "secret"))
+			require.NoError(t, err, name)
+			assert.False(t, introspection.Active, name)
+		}
+		_, err = oauth.Introspect(ctx, client, config.IntrospectionEndpoint, token.AccessToken, nil)
+		assert.ErrorContains(t, err, "invalid_client")
+		_, err = oauth.UserInfo(ctx, client, config.UserinfoEndpoint, token.IDToken)
+		assert.ErrorContains(t, err, "invalid_token")
+	})
+}
+
+func TestNew(t *testing.T) {
+	serviceAccountKey := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "kid", UserID: "user"})
+	tests := []struct {
+		name   string
+		issuer string
+		config mockop.Config
+	}{
+		{
+			name: "no issuer",
+		},
+		{
+			name:   "negative lifetime",
+			issuer: "http://localhost",
+			config: mockop.Config{TokenLifetime: -time.Minute},
+		},
+		{
+			name:   "invalid key file",
+			issuer: "http://localhost",
+			config: mockop.Config{Keys: [][]byte{[]byte(`{"type":"serviceaccount","keyId":"kid"}`)}},
+		},
+		{
+			name:   "duplicate key",
+			issuer: "http://localhost",
+			config: mockop.Config{Keys: [][]byte{serviceAccountKey, serviceAccountKey}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mockop.New(tt.issuer, tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOP_userGrants(t *testing.T) {
+	op := mockoptest.Start(t, mockop.Config{
+		Clients: map[string]string{"confidential": "secret"},
+		User:    "user",
+	})
+	denied := mockoptest.Start(t, mockop.Config{})
+	ctx := context.Background()
+	// the redirects are not followed, as there is no client listening on the redirect URI
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	const redirectURI = "http://127.0.0.1:8080/callback"
+
+	// authorize returns the query of the redirect of an authorization request for the public client
+	authorize := func(t *testing.T, op *mockop.OP, pkce *oauth.PKCE) url.Values {
+		u, err := oauth.AuthorizeURL(op.Issuer()+mockop.AuthorizePath, &oauth.AuthorizeRequest{
+			ClientID:    "public",
+			RedirectURI: redirectURI,
+			Scopes:      []string{"openid"},
+			State:       "state",
+			Nonce:       "nonce",
+			PKCE:        pkce,
+		})
+		require.NoError(t, err)
+		resp, err := client.Get(u)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		location, err := url.Parse(resp.Header.Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "state", location.Query().Get("state"))
+		return location.Query()
+	}
+
+	t.Run("authorization code", func(t *testing.T) {
+		pkce, err := oauth.NewPKCE()
+		require.NoError(t, err)
+		code := authorize(t, op, pkce).Get("code")
+		require.NotEmpty(t, code)
+		tokenEndpoint := op.Issuer() + mockop.TokenPath
+
+		_, err = oauth.AuthorizationCode(ctx, client, tokenEndpoint, "other", code, redirectURI, pkce.Verifier, nil)
+		assert.ErrorContains(t, err, "invalid_grant", "other client")
+		code = authorize(t, op, pkce).Get("code")
+		_, err = oauth.AuthorizationCode(ctx, client, tokenEndpoint, "public", code, redirectURI, "wrong", nil)
+		assert.ErrorContains(t, err, "invalid_grant", "wrong verifier")
+
+		code = authorize(t, op, pkce).Get("code")
+		token, err := oauth.AuthorizationCode(ctx, client, tokenEndpoint, "public", code, redirectURI, pkce.Verifier, nil)
+		require.NoError(t, err)
+		claims, err := oauth.VerifyIDToken(token.IDToken, op.Keys(), op.Issuer(), "public", "nonce", time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, "user", claims.Subject)
+		_, err = oauth.AuthorizationCode(ctx, client, tokenEndpoint, "public", code, redirectURI, pkce.Verifier, nil)
+		assert.ErrorContains(t, err, "invalid_grant", "code redeemed twice")
+	})
+
+	t.Run("authorization errors", func(t *testing.T) {
+		assert.Equal(t, "invalid_request", authorize(t, op, nil).Get("error"), "without PKCE")
+		pkce, err := oauth.NewPKCE()
+		require.NoError(t, err)
+		assert.Equal(t, "access_denied", authorize(t, denied, pkce).Get("error"), "without user")
+	})
+
+	t.Run("device authorization", func(t *testing.T) {
+		endpoint, tokenEndpoint := op.Issuer()+mockop.DeviceAuthorizationPath, op.Issuer()+mockop.TokenPath
+		_, err := oauth.DeviceAuthorization(ctx, client, endpoint, "confidential", nil, oauth.ClientSecretBasic("confidential", "wrong"))
+		assert.ErrorContains(t, err, "invalid_client")
+
+		auth := oauth.ClientSecretBasic("confidential", "secret")
+		device, err := oauth.DeviceAuthorization(ctx, client, endpoint, "confidential", []string{"openid"}, auth)
+		require.NoError(t, err)
+		assert.Regexp(t, `^[A-Z]{4}-[A-Z]{4}$`, device.UserCode)
+		token, err := oauth.PollDeviceToken(ctx, client, tokenEndpoint, "confidential", device, auth)
+		require.NoError(t, err)
+		claims, err := oauth.VerifyIDToken(token.IDToken, op.Keys(), op.Issuer(), "confidential", "", time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, "user", claims.Subject)
+
+		device, err = oauth.DeviceAuthorization(ctx, client, denied.Issuer()+mockop.DeviceAuthorizationPath, "public", nil, nil)
+		require.NoError(t, err)
+		_, err = oauth.PollDeviceToken(ctx, client, denied.Issuer()+mockop.TokenPath, "public", device, nil)
+		assert.ErrorContains(t, err, "access_denied")
+	})
+}
+
+func TestOP_tokenExchange(t *testing.T) {
+	serviceAccountKey := keytest.File(t, key.File{Type: key.TypeServiceAccount, KeyID: "sa-key", UserID: "admin"})
+	op := mockoptest.Start(t, mockop.Config{
+		Keys:    [][]byte{serviceAccountKey},
+		Clients: map[string]string{"client": "secret"},
+	})
+	ctx := context.Background()
+	client := http.DefaultClient
+	endpoint, auth := op.Issuer()+mockop.TokenPath, oauth.ClientSecretBasic("client", "secret")
+	subject, err := op.Issue("alice", "app", []string{"openid", "profile"})
+	require.NoError(t, err)
+	actor, err := op.Issue("admin", "app", nil)
+	require.NoError(t, err)
+	actorJWT, err := assertion.FromKey(serviceAccountKey, "", "", &assertion.Options{Audience: []string{op.Issuer()}, Lifetime: time.Minute})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		req       *oauth.TokenExchangeRequest
+		auth      oauth.ClientAuth
+		wantErr   string
+		wantActor string
+	}{
+		{
+			name:    "unauthenticated client",
+			req:     &oauth.TokenExchangeRequest{SubjectToken: subject.AccessToken, SubjectTokenType: oidc.AccessTokenType},
+			auth:    oauth.ClientSecretBasic("client", "wrong"),
+			wantErr: "invalid_client",
+		},
+		{
+			name:    "subject token of other issuer",
+			req:     &oauth.TokenExchangeRequest{SubjectToken: "alice", SubjectTokenType: oidc.AccessTokenType},
+			wantErr: "invalid_request",
+		},
+		{
+			name:    "unsupported requested token type",
+			req:     &oauth.TokenExchangeRequest{SubjectToken: subject.AccessToken, SubjectTokenType: oidc.AccessTokenType, RequestedTokenType: oidc.RefreshTokenType},
+			wantErr: "invalid_request",
+		},
+		{
+			name: "access token",
+			req:  &oauth.TokenExchangeRequest{SubjectToken: subject.AccessToken, SubjectTokenType: oidc.AccessTokenType, Audience: []string{"api"}},
+		},
+		{
+			name:      "impersonation with actor token",
+			req:       &oauth.TokenExchangeRequest{SubjectToken: subject.AccessToken, SubjectTokenType: oidc.AccessTokenType, ActorToken: actor.AccessToken, ActorTokenType: oidc.AccessTokenType, RequestedTokenType: oidc.JWTTokenType},
+			wantActor: "admin",
+		},
+		{
+			name:      "impersonation with signed actor token",
+			req:       &oauth.TokenExchangeRequest{SubjectToken: subject.AccessToken, SubjectTokenType: oidc.AccessTokenType, ActorToken: actorJWT, ActorTokenType: oidc.JWTTokenType},
+			wantActor: "admin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.auth == nil {
+				tt.auth = auth
+			}
+			token, err := oauth.TokenExchange(ctx, client, endpoint, tt.req, tt.auth)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			introspection, err := oauth.Introspect(ctx, client, op.Issuer()+mockop.IntrospectPath, token.AccessToken, auth)
+			require.NoError(t, err)
+			assert.True(t, introspection.Active)
+			assert.Equal(t, "alice", introspection.Subject)
+			assert.Equal(t, oidc.SpaceDelimitedArray{"openid", "profile"}, introspection.Scope)
+			assert.Subset(t, introspection.Audience, tt.req.Audience)
+			claims := new(struct {
+				Actor json.RawMessage `json:"act"`
+			})
+			require.NoError(t, json.Unmarshal(introspection.Raw, claims))
+			if tt.wantActor == "" {
+				assert.Nil(t, claims.Actor)
+				return
+			}
+			assert.JSONEq(t, `{"sub":"`+tt.wantActor+`"}`, string(claims.Actor))
+		})
+	}
+
+	t.Run("id token", func(t *testing.T) {
+		token, err := oauth.TokenExchange(ctx, client, endpoint, &oauth.TokenExchangeRequest{
+			SubjectToken: subject.IDToken, SubjectTokenType: oidc.IDTokenType, RequestedTokenType: oidc.IDTokenType,
+		}, auth)
+		require.NoError(t, err)
+		assert.Equal(t, string(oidc.IDTokenType), token.IssuedTokenType)
+		claims, err := oauth.VerifyIDToken(token.AccessToken, op.Keys(), op.Issuer(), "client", "", time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, "alice", claims.Subject)
+	})
+}
+
+func TestOP_session(t *testing.T) {
+	op := mockoptest.Start(t, mockop.Config{Clients: map[string]string{"client": "secret"}})
+	ctx := context.Background()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	endpoint := op.Issuer() + mockop.RevokePath
+	active := func(token string) bool {
+		_, err := oauth.UserInfo(ctx, client, op.Issuer()+mockop.UserinfoPath, token)
+		return err == nil
+	}
+
+	t.Run("revoke", func(t *testing.T) {
+		token, err := op.Issue("user", "client", nil)
+		require.NoError(t, err)
+		assert.ErrorContains(t, oauth.Revoke(ctx, client, endpoint, "", token.AccessToken, "", nil), "invalid_client")
+		assert.ErrorContains(t, oauth.Revoke(ctx, client, endpoint, "", token.AccessToken, "", oauth.ClientSecretBasic("client", "wrong")), "invalid_client")
+
+		require.NoError(t, oauth.Revoke(ctx, client, endpoint, "public", token.AccessToken, "", nil))
+		assert.True(t, active(token.AccessToken), "revoked by other client")
+		require.NoError(t, oauth.Revoke(ctx, client, endpoint, "", token.AccessToken, "access_token", oauth.ClientSecretBasic("client", "secret")))
+		assert.False(t, active(token.AccessToken))
+		require.NoError(t, oauth.Revoke(ctx, client, endpoint, "public", "unknown", "", nil))
+	})
+
+	t.Run("end session", func(t *testing.T) {
+		for name, tt := range map[string]struct {
+			query        string
+			wantStatus   int
+			wantLocation string
+		}{
+			"without redirect":            {wantStatus: http.StatusOK},
+			"redirect without client":     {query: "post_logout_redirect_uri=http://localhost/logout", wantStatus: http.StatusBadRequest},
+			"redirect with state":         {query: "client_id=client&post_logout_redirect_uri=http://localhost/logout&state=state", wantStatus: http.StatusFound, wantLocation: "http://localhost/logout?state=state"},
+			"redirect with id token hint": {query: "id_token_hint=token&post_logout_redirect_uri=http://localhost/logout", wantStatus: http.StatusFound, wantLocation: "http://localhost/logout"},
+		} {
+			resp, err := client.Get(op.Issuer() + mockop.EndSessionPath + "?" + tt.query)
+			require.NoError(t, err, name)
+			resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode, name)
+			assert.Equal(t, tt.wantLocation, resp.Header.Get("Location"), name)
+		}
+	})
+}
+
+func TestOP_now(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	op := mockoptest.Start(t, mockop.Config{Now: func() time.Time { return now }})
+	resp, err := http.Get(oauth.DiscoveryURL(op.Issuer()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Tue, 01 Jan 2030 12:00:00 GMT", resp.Header.Get("Date"))
+
+	token, err := op.Issue("user", "user", []string{"openid"})
+	require.NoError(t, err)
+	claims, err := oauth.VerifyIDToken(token.IDToken, op.Keys(), op.Issuer(), "user", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(mockop.DefaultTokenLifetime), claims.Expiration.AsTime().UTC())
+}
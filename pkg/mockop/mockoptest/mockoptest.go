@@ -0,0 +1,26 @@
+// Package mockoptest serves the mock OP of [mockop] in Go tests,
+// so the testing packages are not linked into binaries using [mockop.New].
+package mockoptest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zitadel/zitadel-tools/pkg/mockop"
+)
+
+// Start serves an OP for config on a local test server, which is closed when the test ends.
+// The issuer of the returned OP is the URL of the server.
+func Start(tb testing.TB, config mockop.Config) *mockop.OP {
+	tb.Helper()
+	server := httptest.NewUnstartedServer(nil)
+	op, err := mockop.New("http://"+server.Listener.Addr().String(), config)
+	if err != nil {
+		server.Close()
+		tb.Fatalf("mock OP: %v", err)
+	}
+	server.Config.Handler = op
+	server.Start()
+	tb.Cleanup(server.Close)
+	return op
+}
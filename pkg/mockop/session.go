@@ -0,0 +1,52 @@
+package mockop
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// handleRevoke revokes an access token issued to the client of the request (RFC 7009),
+// which is authenticated if credentials are sent, otherwise a public client identified by its client_id.
+// Unknown, invalid and foreign tokens are answered with success as well, as the RFC requires.
+func (op *OP) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	clientID, err := op.requestClient(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	}
+	claims, err := op.verifyAccessToken(r.PostFormValue("token"))
+	if err == nil && claims["client_id"] == clientID {
+		jti, _ := claims["jti"].(string)
+		op.mu.Lock()
+		op.revoked[jti] = true
+		op.mu.Unlock()
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleEndSession redirects to the post_logout_redirect_uri with the state, as there is no session to end.
+// Like ZITADEL, a redirect requires the client_id or an id_token_hint to identify the client.
+func (op *OP) handleEndSession(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("post_logout_redirect_uri")
+	if redirectURI == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("logged out\n"))
+		return
+	}
+	redirect, err := url.Parse(redirectURI)
+	if err != nil || !redirect.IsAbs() {
+		http.Error(w, "post_logout_redirect_uri must be an absolute URI", http.StatusBadRequest)
+		return
+	}
+	if query.Get("client_id") == "" && query.Get("id_token_hint") == "" {
+		http.Error(w, "client_id or id_token_hint is required with post_logout_redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if state := query.Get("state"); state != "" {
+		params := redirect.Query()
+		params.Set("state", state)
+		redirect.RawQuery = params.Encode()
+	}
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
@@ -0,0 +1,325 @@
+package mockop
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-tools/internal/key"
+	"github.com/zitadel/zitadel-tools/internal/oauth"
+)
+
+// projectAudienceScope matches the scopes adding a project to the audience and captures its ID.
+var projectAudienceScope = regexp.MustCompile(`^urn:zitadel:iam:org:project:id:([^:]+):aud$`)
+
+// Token is a token response of the OP.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+	// IssuedTokenType is the type of the access token issued by a token exchange.
+	IssuedTokenType oidc.TokenType `json:"issued_token_type,omitempty"`
+}
+
+// grantClaims are the claims of an issued token which depend on the grant.
+type grantClaims struct {
+	// nonce of the authorization request is added to the ID token.
+	nonce string
+	// actor is the subject a token exchange issued the token on behalf of, which is added as act claim.
+	actor string
+	// audience is added to the audience of the tokens.
+	audience []string
+}
+
+func (op *OP) handleToken(w http.ResponseWriter, r *http.Request) {
+	scopes := strings.Fields(r.PostFormValue("scope"))
+	if err := oauth.ValidateScopes(scopes); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	}
+	var subject, clientID string
+	var extra grantClaims
+	switch grantType := oidc.GrantType(r.PostFormValue("grant_type")); grantType {
+	case oidc.GrantTypeCode, oidc.GrantTypeDeviceCode:
+		request, err := op.redeem(r, grantType)
+		if err != nil {
+			writeError(w, err.StatusCode, err.Code, err.Description)
+			return
+		}
+		subject, clientID, scopes, extra.nonce = op.config.User, request.clientID, request.scopes, request.nonce
+	case oidc.GrantTypeBearer:
+		file, err := op.verifyAssertion(r.PostFormValue("assertion"), key.TypeServiceAccount)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+			return
+		}
+		subject, clientID = file.UserID, file.UserID
+	case oidc.GrantTypeClientCredentials:
+		id, err := op.authenticateClient(r)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+			return
+		}
+		subject, clientID = id, id
+	case oidc.GrantTypeTokenExchange:
+		op.handleTokenExchange(w, r, scopes)
+		return
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", fmt.Sprintf("grant type %q is not supported", grantType))
+		return
+	}
+	token, err := op.issue(subject, clientID, scopes, extra)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, token)
+}
+
+// Issue signs an access token for subject and clientID with the claims requested by the scopes,
+// without checking any credentials. An ID token is added if the openid scope is requested.
+// Tests can use it to get tokens for any subject, the token endpoint uses it after the grant is verified.
+func (op *OP) Issue(subject, clientID string, scopes []string) (*Token, error) {
+	return op.issue(subject, clientID, scopes, grantClaims{})
+}
+
+// issue is [OP.Issue] with the claims of the grant added.
+func (op *OP) issue(subject, clientID string, scopes []string, extra grantClaims) (*Token, error) {
+	if err := oauth.ValidateScopes(scopes); err != nil {
+		return nil, err
+	}
+	now := op.now()
+	expiry := now.Add(op.config.TokenLifetime)
+	audience := slices.Compact(append(op.audience(clientID, scopes), extra.audience...))
+	roles := op.roles(scopes)
+
+	jti, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]any{
+		"iss":       op.issuer,
+		"sub":       subject,
+		"aud":       audience,
+		"client_id": clientID,
+		"iat":       now.Unix(),
+		"nbf":       now.Unix(),
+		"exp":       expiry.Unix(),
+		"jti":       jti,
+	}
+	if len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+	if extra.actor != "" {
+		claims["act"] = map[string]string{"sub": extra.actor}
+	}
+	op.addRoles(claims, roles)
+	token := &Token{
+		TokenType: oidc.BearerToken,
+		ExpiresIn: int64(op.config.TokenLifetime.Seconds()),
+		Scope:     strings.Join(scopes, " "),
+	}
+	if token.AccessToken, err = sign(op.accessTokens, claims); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		return token, nil
+	}
+
+	claims = make(map[string]any)
+	maps.Copy(claims, op.config.Claims[subject])
+	maps.Copy(claims, map[string]any{
+		"iss": op.issuer,
+		"sub": subject,
+		"aud": audience,
+		"azp": clientID,
+		"iat": now.Unix(),
+		"exp": expiry.Unix(),
+	})
+	if extra.nonce != "" {
+		claims["nonce"] = extra.nonce
+	}
+	if extra.actor != "" {
+		claims["act"] = map[string]string{"sub": extra.actor}
+	}
+	op.addRoles(claims, roles)
+	if token.IDToken, err = sign(op.idTokens, claims); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// audience returns the client, the configured project and the projects requested by the audience scopes.
+func (op *OP) audience(clientID string, scopes []string) []string {
+	audience := []string{clientID}
+	if op.config.ProjectID != "" {
+		audience = append(audience, op.config.ProjectID)
+	}
+	for _, scope := range scopes {
+		if match := projectAudienceScope.FindStringSubmatch(scope); match != nil {
+			audience = append(audience, match[1])
+		}
+	}
+	return slices.Compact(audience)
+}
+
+// roles returns the configured roles requested by the scopes:
+// all roles for [oauth.ScopeAllProjectRoles], otherwise those of the role scopes.
+// The organizations are restricted to the one of an organization ID scope.
+// It returns nil if no roles are requested.
+func (op *OP) roles(scopes []string) oauth.Roles {
+	all := slices.Contains(scopes, oauth.ScopeAllProjectRoles)
+	var requested []string
+	var orgID string
+	for _, scope := range scopes {
+		if roleKey, ok := strings.CutPrefix(scope, oauth.ScopeRolePrefix); ok {
+			requested = append(requested, roleKey)
+		}
+		if id, ok := strings.CutPrefix(scope, oauth.ScopeOrgIDPrefix); ok {
+			orgID = id
+		}
+	}
+	if !all && len(requested) == 0 {
+		return nil
+	}
+	roles := make(oauth.Roles)
+	for roleKey, orgs := range op.config.Roles {
+		if !all && !slices.Contains(requested, roleKey) {
+			continue
+		}
+		granted := make(map[string]string, len(orgs))
+		for id, domain := range orgs {
+			if orgID == "" || id == orgID {
+				granted[id] = domain
+			}
+		}
+		if len(granted) > 0 {
+			roles[roleKey] = granted
+		}
+	}
+	return roles
+}
+
+// addRoles sets the roles claim and the project specific roles claim of the configured project.
+func (op *OP) addRoles(claims map[string]any, roles oauth.Roles) {
+	if roles == nil {
+		return
+	}
+	claims[oauth.ClaimProjectRoles] = roles
+	if op.config.ProjectID != "" {
+		claims["urn:zitadel:iam:org:project:"+op.config.ProjectID+":roles"] = roles
+	}
+}
+
+func sign(signer jose.Signer, claims map[string]any) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshal claims: %w", err)
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return signed.CompactSerialize()
+}
+
+func newTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generate jti: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
+
+// verifyAccessToken verifies the signature of an access token issued by the OP
+// and checks its expiry and that it is not revoked. It returns the claims of the token.
+func (op *OP) verifyAccessToken(token string) (map[string]any, error) {
+	claims, err := op.verifyToken(token, "at+jwt")
+	if err != nil {
+		return nil, err
+	}
+	jti, _ := claims["jti"].(string)
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.revoked[jti] {
+		return nil, errors.New("token revoked")
+	}
+	return claims, nil
+}
+
+// verifyToken verifies the signature of a token issued by the OP with the typ header typ and checks its expiry.
+// It returns the claims of the token.
+func (op *OP) verifyToken(token, typ string) (map[string]any, error) {
+	sig, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.SignatureAlgorithm(op.jwks.Keys[0].Algorithm)})
+	if err != nil {
+		return nil, err
+	}
+	if got, _ := sig.Signatures[0].Header.ExtraHeaders[jose.HeaderType].(string); got != typ {
+		return nil, fmt.Errorf("typ %q is not %q", got, typ)
+	}
+	payload, err := sig.Verify(&op.jwks.Keys[0])
+	if err != nil {
+		return nil, err
+	}
+	claims := make(map[string]any)
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+	exp, _ := claims["exp"].(float64)
+	if op.now().Unix() >= int64(exp) {
+		return nil, errors.New("token expired")
+	}
+	return claims, nil
+}
+
+func (op *OP) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	if _, err := op.authenticateClient(r); err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	}
+	claims, err := op.verifyAccessToken(r.PostFormValue("token"))
+	if err != nil {
+		writeJSON(w, map[string]bool{"active": false})
+		return
+	}
+	claims["active"] = true
+	claims["token_type"] = oidc.BearerToken
+	writeJSON(w, claims)
+}
+
+func (op *OP) handleUserinfo(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_request", error_description="no bearer token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	claims, err := op.verifyAccessToken(token)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, err.Error()))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	subject, _ := claims["sub"].(string)
+	userinfo := make(map[string]any)
+	maps.Copy(userinfo, op.config.Claims[subject])
+	userinfo["sub"] = subject
+	for name, value := range claims {
+		if strings.HasPrefix(name, "urn:zitadel:iam:org:project:") && strings.HasSuffix(name, ":roles") {
+			userinfo[name] = value
+		}
+	}
+	writeJSON(w, userinfo)
+}